@@ -0,0 +1,32 @@
+package cloudfront
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/sunil-saini/astat/internal/render"
+)
+
+var describeCmd = &cobra.Command{
+	Use:   "describe <id-or-name>",
+	Short: "Show every detail of a CloudFront distribution",
+	Long: `Show every detail of a CloudFront distribution, including tags and the
+cached resources related to it
+
+The resource is looked up by distribution ID in the cache first,
+then directly in AWS when it is not cached.
+
+Examples:
+  # Describe by distribution ID
+  astat cloudfront describe E2ABCDEFGHIJKL
+
+  # Output as JSON
+  astat cloudfront describe E2ABCDEFGHIJKL --output json`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: render.CompleteIDs("cloudfront"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return render.Describe(cmd, args[0], "cloudfront")
+	},
+}
+
+func init() {
+	CloudFrontCmd.AddCommand(describeCmd)
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/sunil-saini/astat/internal/cache"
+	"github.com/sunil-saini/astat/internal/daemon"
+	"github.com/sunil-saini/astat/internal/logger"
+	"github.com/sunil-saini/astat/internal/refresh"
+)
+
+// daemonWait is how long start and stop wait for the daemon to come up or
+// go away
+const daemonWait = 5 * time.Second
+
+var daemonCmd = &cobra.Command{
+	Use:     "daemon",
+	Short:   "Keep the cache refreshed in the background",
+	GroupID: "project",
+	Long: `Run a background process refreshing every enabled service once stale,
+according to its TTL. While it runs, commands finding stale data ask
+the daemon to refresh it instead of refreshing it themselves, so they
+always return instantly.
+
+The daemon refreshes the profile and regions it was started with,
+commands run for another profile or regions refresh by themselves.
+
+Examples:
+  # Start the daemon for the default profile
+  astat daemon start
+
+  # Start the daemon for another profile and regions
+  astat daemon start --profile prod --regions us-east-1,eu-west-1
+
+  # Check what the daemon is doing, and stop it
+  astat daemon status
+  astat daemon stop`,
+}
+
+var daemonStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start the daemon in the background",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if st, err := daemon.Running(); err == nil {
+			return fmt.Errorf("daemon is already running (PID: %d)", st.PID)
+		}
+
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("cannot locate astat executable: %w", err)
+		}
+		if err := os.MkdirAll(cache.Dir(), 0755); err != nil {
+			return fmt.Errorf("cannot create cache directory: %w", err)
+		}
+		logFile, err := os.OpenFile(daemon.LogPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("cannot open daemon log: %w", err)
+		}
+		defer logFile.Close()
+
+		proc := exec.Command(exe, append([]string{"daemon", "run"}, daemonFlags(cmd)...)...)
+		proc.Stdout = logFile
+		proc.Stderr = logFile
+		proc.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+		if err := proc.Start(); err != nil {
+			return fmt.Errorf("cannot start daemon: %w", err)
+		}
+		pid := proc.Process.Pid
+		_ = proc.Process.Release()
+
+		for deadline := time.Now().Add(daemonWait); time.Now().Before(deadline); time.Sleep(100 * time.Millisecond) {
+			if _, err := daemon.Running(); err == nil {
+				logger.Success("Daemon started (PID: %d), logging to %s", pid, daemon.LogPath())
+				return nil
+			}
+		}
+		return fmt.Errorf("daemon did not start, see %s", daemon.LogPath())
+	},
+}
+
+var daemonStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the running daemon",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		st, err := daemon.Running()
+		if err != nil {
+			logger.Info("Daemon is not running")
+			return nil
+		}
+		if _, err := daemon.Send(daemon.Request{Command: daemon.CommandStop}); err != nil {
+			return fmt.Errorf("cannot stop daemon: %w", err)
+		}
+
+		for deadline := time.Now().Add(daemonWait); time.Now().Before(deadline); time.Sleep(100 * time.Millisecond) {
+			if _, err := os.Stat(daemon.SocketPath()); errors.Is(err, os.ErrNotExist) {
+				logger.Success("Daemon stopped (PID: %d)", st.PID)
+				return nil
+			}
+		}
+		logger.Warn("Daemon (PID: %d) is still finishing its refreshes", st.PID)
+		return nil
+	},
+}
+
+var daemonStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the daemon runs and what it refreshes",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		st, err := daemon.Running()
+		if err != nil {
+			logger.Info("Daemon is not running")
+			return
+		}
+
+		fmt.Printf("PID:        %d\n", st.PID)
+		fmt.Printf("Uptime:     %s\n", time.Since(st.Started).Round(time.Second))
+		fmt.Printf("Account:    %s\n", valueOr(st.Scope.AccountID, "-"))
+		fmt.Printf("Profile:    %s\n", valueOr(st.Scope.Profile, "default"))
+		if len(st.Scope.Regions) > 0 {
+			fmt.Printf("Regions:    %s\n", strings.Join(st.Scope.Regions, ", "))
+		} else {
+			fmt.Printf("Region:     %s\n", valueOr(st.Scope.Region, "default"))
+		}
+		fmt.Printf("Interval:   %s\n", st.Interval)
+		fmt.Printf("Refreshing: %s\n", valueOr(strings.Join(st.Refreshing, ", "), "-"))
+
+		if len(st.LastRefresh) == 0 {
+			return
+		}
+		fmt.Println("Last refreshes:")
+		services := make([]string, 0, len(st.LastRefresh))
+		for service := range st.LastRefresh {
+			services = append(services, service)
+		}
+		slices.Sort(services)
+		for _, service := range services {
+			t := st.LastRefresh[service]
+			fmt.Printf("  %-20s %s (%s ago)\n", service, t.Format("2006-01-02 15:04:05"), time.Since(t).Round(time.Second))
+		}
+	},
+}
+
+var daemonRunCmd = &cobra.Command{
+	Use:    "run",
+	Short:  "Run the daemon in the foreground",
+	Args:   cobra.NoArgs,
+	Hidden: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if st, err := daemon.Running(); err == nil {
+			return fmt.Errorf("daemon is already running (PID: %d)", st.PID)
+		}
+
+		// Nothing answered, so a socket left behind is from a daemon which died
+		socket := daemon.SocketPath()
+		if err := os.Remove(socket); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("cannot remove stale socket: %w", err)
+		}
+		if err := os.MkdirAll(cache.Dir(), 0755); err != nil {
+			return fmt.Errorf("cannot create cache directory: %w", err)
+		}
+
+		ln, err := net.Listen("unix", socket)
+		if err != nil {
+			return fmt.Errorf("cannot listen on %s: %w", socket, err)
+		}
+		defer os.Remove(socket)
+		if err := os.Chmod(socket, 0600); err != nil {
+			ln.Close()
+			return fmt.Errorf("cannot restrict socket permissions: %w", err)
+		}
+
+		return refresh.RunDaemon(cmd.Context(), ln)
+	},
+}
+
+// daemonFlags returns the global flags given to start, passed on to the
+// daemon so it refreshes the same scope
+func daemonFlags(cmd *cobra.Command) []string {
+	var flags []string
+	for _, name := range []string{"config", "profile", "region", "ttl"} {
+		if f := cmd.Flags().Lookup(name); f != nil && f.Changed {
+			flags = append(flags, "--"+name, f.Value.String())
+		}
+	}
+	if f := cmd.Flags().Lookup("regions"); f != nil && f.Changed {
+		flags = append(flags, "--regions", strings.Join(viper.GetStringSlice("regions"), ","))
+	}
+	return flags
+}
+
+func valueOr(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+func init() {
+	daemonCmd.AddCommand(daemonStartCmd)
+	daemonCmd.AddCommand(daemonStopCmd)
+	daemonCmd.AddCommand(daemonStatusCmd)
+	daemonCmd.AddCommand(daemonRunCmd)
+}
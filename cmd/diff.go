@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/sunil-saini/astat/internal/refresh"
+	"github.com/sunil-saini/astat/internal/render"
+)
+
+var (
+	diffOpts render.DiffOptions
+	diffList bool
+)
+
+var diffCmd = &cobra.Command{
+	Use:     "diff <service>",
+	Short:   "Show resources added, removed or changed over time",
+	GroupID: "resources",
+	Long: `Compare two versions of the cached data of a service and show the
+resources added, removed or changed between them, matched by their ID.
+
+A snapshot of every service is kept on each refresh, the history-snapshots
+config setting how many per service (10 by default, 0 to disable them). The
+old version is the latest snapshot taken at or before --since ago or --from,
+the new one the latest snapshot at or before --to, or the current cache.
+
+Examples:
+  # EC2 instances added, removed or changed since yesterday
+  astat diff ec2
+
+  # Lambda functions changed in the last week
+  astat diff lambda --since 168h
+
+  # Between two snapshots, listed with --list
+  astat diff ec2 --list
+  astat diff ec2 --from 20240130T090000Z --to 20240131T090000Z
+
+  # Between two dates, as JSON
+  astat diff elb --from 2024-01-30 --to '2024-01-31 12:00' --output json`,
+	Args: cobra.ExactArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return refresh.CompleteServices(toComplete), cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if diffList {
+			return render.Snapshots(cmd.Context(), args[0])
+		}
+		return render.Diff(cmd.Context(), args[0], diffOpts)
+	},
+}
+
+func init() {
+	diffCmd.Flags().DurationVar(&diffOpts.Since, "since", 24*time.Hour, "compare with the data cached this long ago")
+	diffCmd.Flags().StringVar(&diffOpts.From, "from", "", "compare from a snapshot name or time (e.g. 20240131T150405Z, '2024-01-31 15:04')")
+	diffCmd.Flags().StringVar(&diffOpts.To, "to", "", "compare to a snapshot name or time rather than the current cache")
+	diffCmd.Flags().BoolVar(&diffList, "list", false, "list the snapshots of the service")
+	diffCmd.MarkFlagsMutuallyExclusive("since", "from")
+}
@@ -1,12 +1,18 @@
 package domain
 
 import (
+	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"github.com/sunil-saini/astat/internal/aws"
 	"github.com/sunil-saini/astat/internal/model"
+	"github.com/sunil-saini/astat/internal/output"
+	"github.com/sunil-saini/astat/internal/refresh"
 )
 
 var TraceCmd = &cobra.Command{
@@ -23,32 +29,68 @@ The trace includes:
 - Application, Network, and Classic Load Balancers
 - Target Groups and Health Checks
 - Filtered ALB Rules and Conditions
-- Lambda Functions and EC2 Instance names`,
+- Lambda Functions and EC2 Instance names
+
+With --output json or yaml the trace is printed as a tree of nodes, each
+with its Type, Name, ID, Value, Status and Children. --output dot and
+--output mermaid draw it as a graph for Graphviz or Mermaid, the edges
+green or red with the health of the node they lead to. The command exits
+with a non-zero status when a node of the path is unhealthy with no
+healthy alternative.
+
+The path is resolved from the local cache when the Route53, CloudFront,
+ELB and RDS caches are fresh, target health and domains missing from the
+cached Route53 records still being looked up live. --cached always traces
+from the cache and looks nothing up live, so tracing works offline: target
+health is shown as unavailable and not checked then. --live always asks
+AWS.
+
+Examples:
+  astat domain trace api.example.com
+  astat domain trace api.example.com --cached
+  astat domain trace api.example.com --output json | jq '.Hops'
+  astat domain trace api.example.com --output dot | dot -Tsvg > api.svg`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		domain := args[0]
-		spinner, _ := pterm.DefaultSpinner.
-			WithSequence("⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏").
-			WithRemoveWhenDone(true).
-			Start(pterm.Cyan(fmt.Sprintf("Tracing %s...", domain)))
-
-		ctx := cmd.Context()
-		cfg, err := aws.LoadConfig(ctx)
+		format, arg, err := output.ParseFormat(viper.GetString("output"))
 		if err != nil {
-			spinner.Fail(err)
 			return err
 		}
+		if !slices.Contains(traceFormats, format) {
+			return fmt.Errorf("output format %s is not supported by domain trace, use table, json, ndjson, yaml, dot, mermaid or a template", format)
+		}
+
+		ctx := cmd.Context()
+		opts := traceOptions(cmd)
+		if format != output.Table {
+			result, err := trace(ctx, domain, opts)
+			if err != nil {
+				return err
+			}
+			if err := printResult(format, arg, result); err != nil {
+				return err
+			}
+			return unhealthyError(result)
+		}
+
+		spinner, _ := pterm.DefaultSpinner.
+			WithSequence("⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏").
+			WithRemoveWhenDone(true).
+			Start(pterm.Cyan(fmt.Sprintf("Tracing %s%s...", domain, sourceSuffix(opts))))
 
-		result, err := aws.TraceDomain(ctx, cfg, domain)
+		result, err := trace(ctx, domain, opts)
 		if err != nil {
 			spinner.Fail(err)
 			return err
 		}
 
 		spinner.Stop()
-		pterm.Success.Printf("Trace complete for %s\n", pterm.Bold.Sprint(domain))
+		pterm.Success.Printf("Trace complete for %s%s\n", pterm.Bold.Sprint(domain), sourceSuffix(opts))
 		pterm.Println()
 
+		defer printWarnings(result.Warnings)
+
 		if len(result.Hops) == 0 {
 			pterm.Warning.Println("No path found for domain")
 			return nil
@@ -63,10 +105,134 @@ The trace includes:
 		}
 
 		pterm.DefaultTree.WithRoot(root).Render()
-		return nil
+		return unhealthyError(result)
 	},
 }
 
+// traceFormats are the output formats a trace can be printed in, the
+// tabular ones having no rows to print
+var traceFormats = []output.Format{
+	output.Table, output.JSON, output.NDJSON, output.YAML,
+	output.GoTemplate, output.GoTemplateFile, output.JSONPathTemplate, output.JSONPathFile,
+	output.DOT, output.Mermaid,
+}
+
+// traceOptions traces from the cache with --cached, live with --live, and
+// otherwise from the cache when every service a trace reads is fresh
+func traceOptions(cmd *cobra.Command) aws.TraceOptions {
+	offline, _ := cmd.Flags().GetBool("cached")
+	live, _ := cmd.Flags().GetBool("live")
+	cached := offline
+	if !cached && !live {
+		cached = refresh.Fresh(cmd.Context(), aws.TraceServices...)
+	}
+	return aws.TraceOptions{Cached: cached, Offline: offline, Enabled: refresh.Enabled}
+}
+
+func sourceSuffix(opts aws.TraceOptions) string {
+	if opts.Cached {
+		return " from cache"
+	}
+	return ""
+}
+
+func trace(ctx context.Context, domain string, opts aws.TraceOptions) (*model.TraceResult, error) {
+	cfg, err := aws.LoadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return aws.TraceDomain(ctx, cfg, domain, opts)
+}
+
+// printResult prints the trace in a machine-readable format
+func printResult(format output.Format, arg string, result *model.TraceResult) error {
+	if result.Hops == nil {
+		result.Hops = []model.TraceNode{}
+	}
+
+	switch format {
+	case output.JSON:
+		return output.PrintJSON(result)
+	case output.NDJSON:
+		return output.PrintNDJSON(result)
+	case output.YAML:
+		return output.PrintYAML(result)
+	case output.DOT:
+		return output.PrintDOT(traceGraph(result))
+	case output.Mermaid:
+		return output.PrintMermaid(traceGraph(result))
+	default:
+		return output.PrintTemplate(format, arg, result)
+	}
+}
+
+// nodeShapes draws each type of node with its own shape, other types being
+// boxes
+var nodeShapes = map[string]output.Shape{
+	model.NodeDNS:         output.ShapeEllipse,
+	model.NodeRoute53:     output.ShapeHexagon,
+	model.NodeCloudFront:  output.ShapeParallelogram,
+	model.NodeOrigin:      output.ShapeRounded,
+	model.NodeALB:         output.ShapeDiamond,
+	model.NodeNLB:         output.ShapeDiamond,
+	model.NodeCLB:         output.ShapeDiamond,
+	model.NodeTargetGroup: output.ShapeRounded,
+	model.NodeTarget:      output.ShapeCircle,
+	model.NodeInstance:    output.ShapeCircle,
+	model.NodeRDS:         output.ShapeCylinder,
+	model.NodeRDSInstance: output.ShapeCylinder,
+}
+
+// edgeColors colors the edge into a node by its health
+var edgeColors = map[string]string{
+	model.StatusHealthy:     "#2e7d32",
+	model.StatusUnhealthy:   "#c62828",
+	model.StatusUnavailable: "#9e9e9e",
+}
+
+// traceGraph turns the trace into a graph from the domain to every node of
+// its path, the edges colored by the health of the node they lead to
+func traceGraph(result *model.TraceResult) output.Graph {
+	g := output.Graph{Nodes: []output.GraphNode{{ID: "n0", Label: result.Domain, Shape: output.ShapeEllipse}}}
+
+	var walk func(parent string, nodes []model.TraceNode)
+	walk = func(parent string, nodes []model.TraceNode) {
+		for _, n := range nodes {
+			id := fmt.Sprintf("n%d", len(g.Nodes))
+			label := fmt.Sprintf("[%s] %s", n.Type, n.Name)
+			if n.Value != "" {
+				label += "\n" + n.Value
+			}
+			shape, ok := nodeShapes[n.Type]
+			if !ok {
+				shape = output.ShapeBox
+			}
+
+			g.Nodes = append(g.Nodes, output.GraphNode{ID: id, Label: label, Shape: shape})
+			g.Edges = append(g.Edges, output.GraphEdge{From: parent, To: id, Color: edgeColors[n.Status]})
+			walk(id, n.Children)
+		}
+	}
+	walk("n0", result.Hops)
+	return g
+}
+
+// unhealthyError fails the command when a node of the trace is unhealthy
+// with no healthy alternative, so scripts can use the exit code as a health
+// check
+func unhealthyError(result *model.TraceResult) error {
+	nodes := result.Unhealthy()
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(nodes))
+	for i, n := range nodes {
+		names[i] = fmt.Sprintf("%s %s", n.Type, n.Name)
+	}
+	return fmt.Errorf("%s has %d unhealthy node(s) on its path: %s", result.Domain, len(nodes), strings.Join(names, ", "))
+}
+
 func convertToPTermNode(node model.TraceNode) pterm.TreeNode {
 	name := pterm.Bold.Sprint(node.Name)
 	val := node.Value
@@ -74,12 +240,12 @@ func convertToPTermNode(node model.TraceNode) pterm.TreeNode {
 
 	// Apply coloring based on status
 	switch status {
-	case "healthy":
+	case model.StatusHealthy:
 		name = pterm.LightGreen(node.Name)
 		if val != "" {
 			val = pterm.LightGreen(val)
 		}
-	case "unhealthy":
+	case model.StatusUnhealthy:
 		name = pterm.LightRed(node.Name)
 		if val != "" {
 			val = pterm.LightRed(val)
@@ -106,6 +272,21 @@ func convertToPTermNode(node model.TraceNode) pterm.TreeNode {
 	return pnode
 }
 
+// printWarnings lists the lookups that failed during a trace, which may have
+// left it incomplete
+func printWarnings(warnings []string) {
+	if len(warnings) == 0 {
+		return
+	}
+	pterm.Println()
+	for _, w := range warnings {
+		pterm.Warning.Println(w)
+	}
+}
+
 func init() {
+	TraceCmd.Flags().Bool("cached", false, "trace from the local cache only, without calling AWS")
+	TraceCmd.Flags().Bool("live", false, "trace with live AWS calls even when the cache is fresh")
+	TraceCmd.MarkFlagsMutuallyExclusive("cached", "live")
 	DomainCmd.AddCommand(TraceCmd)
 }
@@ -0,0 +1,33 @@
+package ec2
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/sunil-saini/astat/internal/render"
+)
+
+var describeCmd = &cobra.Command{
+	Use:   "describe <id-or-name>",
+	Short: "Show every detail of an EC2 instance",
+	Long: `Show every detail of an EC2 instance, including tags and the
+cached resources related to it
+
+The resource is looked up by instance ID or Name tag in the cache first,
+then directly in AWS when it is not cached.
+
+Examples:
+  # Describe by instance ID or Name tag
+  astat ec2 describe i-0123456789abcdef0
+  astat ec2 describe web-server
+
+  # Output as JSON
+  astat ec2 describe i-0123456789abcdef0 --output json`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: render.CompleteIDs("ec2"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return render.Describe(cmd, args[0], "ec2")
+	},
+}
+
+func init() {
+	EC2Cmd.AddCommand(describeCmd)
+}
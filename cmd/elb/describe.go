@@ -0,0 +1,32 @@
+package elb
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/sunil-saini/astat/internal/render"
+)
+
+var describeCmd = &cobra.Command{
+	Use:   "describe <id-or-name>",
+	Short: "Show every detail of a load balancer or target group",
+	Long: `Show every detail of a load balancer or target group, including its
+listeners and rules, tags and the cached resources related to it
+
+The resource is looked up by name or ARN in the cache first, then directly in
+AWS when it is not cached.
+
+Examples:
+  # Describe by load balancer name or ARN
+  astat elb describe my-alb
+
+  # Output as JSON
+  astat elb describe my-alb --output json`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: render.CompleteIDs("elb", "elb-target-groups"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return render.Describe(cmd, args[0], "elb", "elb-target-groups")
+	},
+}
+
+func init() {
+	ElbCmd.AddCommand(describeCmd)
+}
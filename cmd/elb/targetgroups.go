@@ -0,0 +1,27 @@
+package elb
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/sunil-saini/astat/internal/render"
+)
+
+var targetGroupsCmd = &cobra.Command{
+	Use:     "target-groups",
+	Aliases: []string{"tg"},
+	Short:   "List all target groups",
+	Long: `List all target groups of the load balancers
+
+Examples:
+  # List all target groups
+  astat elb target-groups
+
+  # Force refresh from AWS
+  astat elb tg --refresh`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return render.List(cmd, args, "elb-target-groups")
+	},
+}
+
+func init() {
+	ElbCmd.AddCommand(targetGroupsCmd)
+}
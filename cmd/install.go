@@ -7,6 +7,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/sunil-saini/astat/internal/refresh"
 	"gopkg.in/yaml.v3"
 )
 
@@ -91,7 +92,7 @@ func installConfig(ui UI) string {
 
 	if _, err := os.Stat(configFile); os.IsNotExist(err) {
 		settings := map[string]any{
-			"ttl":                 viper.GetDuration("ttl").String(),
+			"ttl":                 refresh.TTL("").String(),
 			"auto-refresh":        viper.GetBool("auto-refresh"),
 			"output":              viper.GetString("output"),
 			"route53-max-records": viper.GetInt("route53-max-records"),
@@ -0,0 +1,32 @@
+package lambda
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/sunil-saini/astat/internal/render"
+)
+
+var describeCmd = &cobra.Command{
+	Use:   "describe <id-or-name>",
+	Short: "Show every detail of a Lambda function",
+	Long: `Show every detail of a Lambda function, including tags and the
+cached resources related to it
+
+The resource is looked up by function name or ARN in the cache first,
+then directly in AWS when it is not cached.
+
+Examples:
+  # Describe by function name or ARN
+  astat lambda describe payments-api
+
+  # Output as JSON
+  astat lambda describe payments-api --output json`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: render.CompleteIDs("lambda"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return render.Describe(cmd, args[0], "lambda")
+	},
+}
+
+func init() {
+	LambdaCmd.AddCommand(describeCmd)
+}
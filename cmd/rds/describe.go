@@ -0,0 +1,32 @@
+package rds
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/sunil-saini/astat/internal/render"
+)
+
+var describeCmd = &cobra.Command{
+	Use:   "describe <id-or-name>",
+	Short: "Show every detail of an RDS cluster or instance",
+	Long: `Show every detail of an RDS cluster or instance, including tags and the
+cached resources related to it
+
+The resource is looked up by cluster or instance identifier in the cache first,
+then directly in AWS when it is not cached.
+
+Examples:
+  # Describe by cluster or instance identifier
+  astat rds describe orders-db
+
+  # Output as JSON
+  astat rds describe orders-db --output json`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: render.CompleteIDs("rds-clusters", "rds-instances"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return render.Describe(cmd, args[0], "rds-clusters", "rds-instances")
+	},
+}
+
+func init() {
+	RDSCmd.AddCommand(describeCmd)
+}
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"sync"
 
 	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
@@ -10,28 +12,74 @@ import (
 	"github.com/sunil-saini/astat/internal/registry"
 )
 
+var refreshExclude []string
+
 var refreshCmd = &cobra.Command{
-	Use:     "refresh",
-	Short:   "Refresh all services",
-	Long:    "Refresh cache for all AWS services",
+	Use:   "refresh [service...]",
+	Short: "Refresh all or some services",
+	Long: `Refresh cache for all AWS services, or for the services given by name or
+glob. Only the services listed in the services.enabled config are refreshed
+by default, a service named exactly is refreshed even when not enabled.
+
+Examples:
+  # Refresh every enabled service
+  astat refresh
+
+  # Refresh EC2 and both Route53 services
+  astat refresh ec2 'route53-*'
+
+  # Refresh everything but CloudFront and SQS
+  astat refresh --exclude cloudfront,sqs`,
 	GroupID: "project",
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return refresh.CompleteServices(toComplete), cobra.ShellCompDirectiveNoFileComp
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := cmd.Context()
 		ui := NewUI()
 
+		services, err := refresh.SelectServices(args, refreshExclude)
+		if err != nil {
+			ui.Error(err.Error())
+			return
+		}
+		if len(services) == 0 {
+			ui.Warning("No service to refresh, check the services.enabled config and --exclude")
+			return
+		}
+
+		names := make([]string, 0, len(services))
+		for _, s := range services {
+			names = append(names, s.Name)
+		}
+
 		ui.Println()
-		ui.Info("Refreshing all services...")
+		if len(services) == len(registry.Registry) {
+			ui.Info("Refreshing all services...")
+		} else {
+			ui.Info(fmt.Sprintf("Refreshing %s...", strings.Join(names, ", ")))
+		}
 		ui.Println()
 
 		ui.StartRefresh()
 		defer ui.StopRefresh()
 
+		// Services beyond the concurrency limit wait, shown as pending
+		var sem chan struct{}
+		if limit := refresh.ServiceConcurrency(); limit > 0 {
+			sem = make(chan struct{}, limit)
+		}
+
 		var wg sync.WaitGroup
-		for _, svc := range registry.Registry {
+		for _, svc := range services {
 			wg.Add(1)
 			go func(service registry.Service) {
 				defer wg.Done()
 				tracker := ui.GetRefreshTracker(service.Name)
+				if sem != nil {
+					sem <- struct{}{}
+					defer func() { <-sem }()
+				}
 				refresh.Refresh(ctx, service.Name, func(ctx context.Context, cfg sdkaws.Config) (any, error) {
 					return service.Fetch(ctx, cfg)
 				}, tracker)
@@ -40,6 +88,14 @@ var refreshCmd = &cobra.Command{
 		wg.Wait()
 
 		ui.Println()
-		ui.Success("All services refreshed!")
+		if len(services) == len(registry.Registry) {
+			ui.Success("All services refreshed!")
+		} else {
+			ui.Success("Services refreshed!")
+		}
 	},
 }
+
+func init() {
+	refreshCmd.Flags().StringSliceVar(&refreshExclude, "exclude", nil, "services not to refresh, by name or glob (e.g. cloudfront,rds-*)")
+}
@@ -7,7 +7,6 @@ import (
 	"os/signal"
 	"path/filepath"
 	"syscall"
-	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -22,8 +21,10 @@ import (
 	"github.com/sunil-saini/astat/cmd/s3"
 	"github.com/sunil-saini/astat/cmd/sqs"
 	"github.com/sunil-saini/astat/cmd/ssm"
+	"github.com/sunil-saini/astat/internal/cache"
 	"github.com/sunil-saini/astat/internal/logger"
 	"github.com/sunil-saini/astat/internal/refresh"
+	"github.com/sunil-saini/astat/internal/render"
 )
 
 var (
@@ -53,9 +54,21 @@ Example workflow:
   $ astat ec2 list              		# List EC2 instances (instant!)
   $ astat ec2 ls              			# Alias for 'list'
   $ astat ec2 ls <search-text>			# Search EC2 instances with matching search text
+  $ astat ec2 ls --filter State=running		# Filter EC2 instances by field
+  $ astat ec2 ls --tag env=prod --tag-key team	# Filter EC2 instances by tags
   $ astat s3 list --refresh     		# Force refresh S3 buckets
+  $ astat ec2 ls web --watch    		# Watch EC2 instances change live
+  $ astat search 10.2.3.4       		# Search every cached service
+  $ astat tui                   		# Browse cached resources full-screen
+  $ astat diff ec2 --since 24h  		# EC2 instances added, removed or changed
 
 Learn more: https://github.com/sunil-saini/astat`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if _, _, err := refresh.ParseTTLSpec(viper.GetString(refresh.TTLOverrideKey)); err != nil {
+			return fmt.Errorf("--ttl: %w", err)
+		}
+		return nil
+	},
 	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
 		if isQuietCommand(cmd) {
 			return nil
@@ -72,7 +85,7 @@ Learn more: https://github.com/sunil-saini/astat`,
 				curr = curr.Parent()
 			}
 
-			if service != "" && !isQuietCommand(curr) && service != "domain" {
+			if service != "" && !isQuietCommand(curr) && service != "domain" && service != "search" && service != "diff" && service != "tui" {
 				switch service {
 				case "route53":
 					if cmd.Name() == "list" || cmd.Name() == "ls" {
@@ -80,6 +93,12 @@ Learn more: https://github.com/sunil-saini/astat`,
 					} else if cmd.Name() == "records" {
 						refresh.AutoRefreshIfStale(cmd.Context(), "route53-records")
 					}
+				case "elb":
+					if cmd.Name() == "target-groups" {
+						refresh.AutoRefreshIfStale(cmd.Context(), "elb-target-groups")
+					} else {
+						refresh.AutoRefreshIfStale(cmd.Context(), "elb")
+					}
 				case "rds":
 					if cmd.Name() == "list" || cmd.Name() == "ls" {
 						refresh.AutoRefreshIfStale(cmd.Context(), "rds-clusters")
@@ -119,24 +138,46 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ~/.config/astat/config.yaml)")
 	rootCmd.PersistentFlags().String("profile", "", "AWS profile")
 	rootCmd.PersistentFlags().String("region", "", "AWS region")
-	rootCmd.PersistentFlags().String("output", "table", "output format: table|json")
+	rootCmd.PersistentFlags().StringSlice("regions", nil, "AWS regions to fetch and list regional services from, or 'all' for every enabled region")
+	rootCmd.PersistentFlags().String("output", "table", "output format: table|json|ndjson|yaml|csv|tsv|markdown|go-template=...|go-template-file=...|jsonpath=...|jsonpath-file=...|dot|mermaid")
 	rootCmd.PersistentFlags().Bool("refresh", false, "refresh data from AWS")
-	rootCmd.PersistentFlags().Duration("ttl", 24*time.Hour, "cache TTL")
+	rootCmd.PersistentFlags().String("filter", "", "filter listed resources by field, e.g. 'State=running,Type~^t3,AZ!=us-east-1a'")
+	rootCmd.PersistentFlags().StringSlice("columns", nil, "columns to list, by header or field name (e.g. Name,State,PrivateIP)")
+	rootCmd.PersistentFlags().String("sort-by", "", "sort listed resources by a field, e.g. 'Launch Time:desc'")
+	rootCmd.PersistentFlags().Bool("wide", false, "list every field of the resources, including those hidden by default")
+	rootCmd.PersistentFlags().StringArray("tag", nil, "list resources having a tag, as key=value (repeatable)")
+	rootCmd.PersistentFlags().StringArray("tag-key", nil, "list resources having a tag key, whatever its value (repeatable)")
+	rootCmd.PersistentFlags().StringSlice("tag-columns", nil, "tag keys to show as table columns (e.g. team,env)")
+	rootCmd.PersistentFlags().String("watch", "", "fetch listed resources live every interval and show what changes (e.g. --watch, --watch=10s)")
+	rootCmd.PersistentFlags().Lookup("watch").NoOptDefVal = render.DefaultWatchInterval
+	rootCmd.PersistentFlags().String("ttl", "", "cache TTL, globally (e.g. 1h) and/or per service (e.g. ec2=10m,route53-records=72h)")
 	rootCmd.PersistentFlags().Bool(autoRefreshFlag, true, "enable auto refresh if stale")
 	rootCmd.PersistentFlags().Int(r53MaxRecordsFlag, 1000, "ignore route53 hosted zones to fetch records with more than max records")
 
 	viper.BindPFlag("profile", rootCmd.PersistentFlags().Lookup("profile"))
 	viper.BindPFlag("region", rootCmd.PersistentFlags().Lookup("region"))
+	viper.BindPFlag("regions", rootCmd.PersistentFlags().Lookup("regions"))
 	viper.BindPFlag("output", rootCmd.PersistentFlags().Lookup("output"))
 	viper.BindPFlag("refresh", rootCmd.PersistentFlags().Lookup("refresh"))
-	viper.BindPFlag("ttl", rootCmd.PersistentFlags().Lookup("ttl"))
+	viper.BindPFlag("filter", rootCmd.PersistentFlags().Lookup("filter"))
+	viper.BindPFlag("columns", rootCmd.PersistentFlags().Lookup("columns"))
+	viper.BindPFlag("sort-by", rootCmd.PersistentFlags().Lookup("sort-by"))
+	viper.BindPFlag("wide", rootCmd.PersistentFlags().Lookup("wide"))
+	viper.BindPFlag("tag", rootCmd.PersistentFlags().Lookup("tag"))
+	viper.BindPFlag("tag-key", rootCmd.PersistentFlags().Lookup("tag-key"))
+	viper.BindPFlag("tag-columns", rootCmd.PersistentFlags().Lookup("tag-columns"))
+	viper.BindPFlag("watch", rootCmd.PersistentFlags().Lookup("watch"))
+	viper.BindPFlag(refresh.TTLOverrideKey, rootCmd.PersistentFlags().Lookup("ttl"))
 	viper.BindPFlag(autoRefreshFlag, rootCmd.PersistentFlags().Lookup(autoRefreshFlag))
 	viper.BindPFlag(r53MaxRecordsFlag, rootCmd.PersistentFlags().Lookup(r53MaxRecordsFlag))
 
 	viper.SetDefault("output", "table")
-	viper.SetDefault("ttl", 24*time.Hour)
+	viper.SetDefault("ttl", refresh.DefaultTTL)
 	viper.SetDefault(autoRefreshFlag, true)
 	viper.SetDefault(r53MaxRecordsFlag, 1000)
+	viper.SetDefault("route53-zone-concurrency", 5)
+	viper.SetDefault("retry-mode", "standard")
+	viper.SetDefault(cache.SnapshotsKey, 10)
 
 	yellow := color.New(color.FgHiYellow).SprintFunc()
 
@@ -159,12 +200,16 @@ func init() {
 	rootCmd.AddCommand(rds.RDSCmd)
 	rootCmd.AddCommand(domain.DomainCmd)
 	rootCmd.AddCommand(sqs.SQSCmd)
+	rootCmd.AddCommand(searchCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(tuiCmd)
 
 	rootCmd.AddCommand(ConfigCmd)
 	rootCmd.AddCommand(completionCmd)
 	rootCmd.AddCommand(installCmd)
 	rootCmd.AddCommand(refreshCmd)
 	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(daemonCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(upgradeCmd)
 
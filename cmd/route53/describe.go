@@ -0,0 +1,33 @@
+package route53
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/sunil-saini/astat/internal/render"
+)
+
+var describeCmd = &cobra.Command{
+	Use:   "describe <id-or-name>",
+	Short: "Show every detail of a Route53 hosted zone or record",
+	Long: `Show every detail of a Route53 hosted zone or record, including tags and the
+cached resources related to it
+
+The resource is looked up by zone ID, zone name or record name in the cache first,
+then directly in AWS when it is not cached.
+
+Examples:
+  # Describe by zone ID, zone name or record name
+  astat route53 describe example.com
+  astat route53 describe api.example.com
+
+  # Output as JSON
+  astat route53 describe example.com --output json`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: render.CompleteIDs("route53-zones", "route53-records"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return render.Describe(cmd, args[0], "route53-zones", "route53-records")
+	},
+}
+
+func init() {
+	Route53Cmd.AddCommand(describeCmd)
+}
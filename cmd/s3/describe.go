@@ -0,0 +1,32 @@
+package s3
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/sunil-saini/astat/internal/render"
+)
+
+var describeCmd = &cobra.Command{
+	Use:   "describe <id-or-name>",
+	Short: "Show every detail of an S3 bucket",
+	Long: `Show every detail of an S3 bucket, including tags and the
+cached resources related to it
+
+The resource is looked up by bucket name in the cache first,
+then directly in AWS when it is not cached.
+
+Examples:
+  # Describe by bucket name
+  astat s3 describe my-assets-bucket
+
+  # Output as JSON
+  astat s3 describe my-assets-bucket --output json`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: render.CompleteIDs("s3"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return render.Describe(cmd, args[0], "s3")
+	},
+}
+
+func init() {
+	S3Cmd.AddCommand(describeCmd)
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/sunil-saini/astat/internal/render"
+)
+
+var searchCmd = &cobra.Command{
+	Use:     "search <term>",
+	Short:   "Search all cached services",
+	GroupID: "resources",
+	Long: `Search every field of all cached services at once
+
+Matching resources are grouped by service, with the field that
+matched and the command listing the resource. Only the local cache
+is searched, run 'astat refresh' to bring it up to date.
+
+Examples:
+  # What is this IP?
+  astat search 10.2.3.4
+
+  # Anything named payments
+  astat search payments
+
+  # As JSON, including the matching resources
+  astat search payments --output json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return render.Search(cmd.Context(), args[0])
+	},
+}
@@ -0,0 +1,32 @@
+package sqs
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/sunil-saini/astat/internal/render"
+)
+
+var describeCmd = &cobra.Command{
+	Use:   "describe <id-or-name>",
+	Short: "Show every detail of an SQS queue",
+	Long: `Show every detail of an SQS queue, including tags and the
+cached resources related to it
+
+The resource is looked up by queue name in the cache first,
+then directly in AWS when it is not cached.
+
+Examples:
+  # Describe by queue name
+  astat sqs describe orders-queue
+
+  # Output as JSON
+  astat sqs describe orders-queue --output json`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: render.CompleteIDs("sqs"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return render.Describe(cmd, args[0], "sqs")
+	},
+}
+
+func init() {
+	SQSCmd.AddCommand(describeCmd)
+}
@@ -0,0 +1,32 @@
+package ssm
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/sunil-saini/astat/internal/render"
+)
+
+var describeCmd = &cobra.Command{
+	Use:   "describe <id-or-name>",
+	Short: "Show every detail of an SSM parameter",
+	Long: `Show every detail of an SSM parameter, including tags and the
+cached resources related to it
+
+The resource is looked up by parameter name in the cache first,
+then directly in AWS when it is not cached.
+
+Examples:
+  # Describe by parameter name
+  astat ssm describe /app/db/host
+
+  # Output as JSON
+  astat ssm describe /app/db/host --output json`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: render.CompleteIDs("ssm"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return render.Describe(cmd, args[0], "ssm")
+	},
+}
+
+func init() {
+	SSMCmd.AddCommand(describeCmd)
+}
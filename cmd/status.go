@@ -2,11 +2,15 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/sunil-saini/astat/internal/aws"
 	"github.com/sunil-saini/astat/internal/cache"
 	"github.com/sunil-saini/astat/internal/logger"
 	"github.com/sunil-saini/astat/internal/refresh"
@@ -22,101 +26,57 @@ var statusCmd = &cobra.Command{
 	GroupID: "project",
 	Long: `Display the status of all cached AWS services.
 
-Shows when each service was last refreshed and whether
-the cache is fresh or stale based on the configured TTL.
+Cached data is kept separately for every AWS account, profile
+and region. For each of them, shows when each service was last
+refreshed and whether the cache is fresh or stale based on the
+configured TTL.
 Also checks for available astat updates.
 
 Examples:
   # Check cache status
   astat status`,
 	Run: func(cmd *cobra.Command, args []string) {
-		var meta cache.Meta
-		metaPath := cache.Path(cache.Dir(), "meta")
 		cacheInitialized := false
-		if err := cache.Read(metaPath, &meta); err != nil {
+		current, err := refresh.CurrentContext(cmd.Context())
+		if err != nil {
+			logger.Warn("Cannot resolve current AWS context: %v", err)
+		} else if !cacheExists(current) {
 			logger.Warn("Cache metadata not found, initializing with defaults...")
-			meta = cache.Meta{
-				LastUpdated: time.Time{},
-				Services:    make(map[string]cache.ServiceMeta),
-			}
-
-			if err := cache.EnsureDir(cache.Dir()); err != nil {
-				logger.Error("Failed to create cache directory: %v", err)
-				return
-			}
-
-			cache.LockMeta()
-			if err := cache.Write(metaPath, &meta); err != nil {
-				cache.UnlockMeta()
+			if err := cache.UpdateMeta(current, func(*cache.Meta) error { return nil }); err != nil {
 				logger.Error("Failed to initialize cache metadata: %v", err)
 				return
 			}
-			cache.UnlockMeta()
 			logger.Success("Cache initialized successfully")
 			cacheInitialized = true
 
 			logger.Info("Triggering initial refresh to populate cache...")
 			refreshCmd.Run(cmd, args)
+		}
 
-			cache.LockMeta()
-			defer cache.UnlockMeta()
-			if err := cache.Read(metaPath, &meta); err != nil {
-				logger.Error("Failed to read cache metadata after refresh: %v", err)
-				return
-			}
+		contexts, err := cache.Contexts()
+		if err != nil {
+			logger.Error("Failed to list cached contexts: %v", err)
+			return
 		}
 
-		ttl := viper.GetDuration("ttl")
 		pterm.DefaultSection.Println("Cache Status")
-		pterm.Printf("%s: %s\n", pterm.LightMagenta("Last Refresh"), pterm.Cyan(meta.LastUpdated.Format(time.RFC1123)))
-		pterm.Printf("%s:          %s\n\n", pterm.LightMagenta("TTL"), pterm.Cyan(ttl))
-
-		data := pterm.TableData{
-			{"Service", "Status", "Age"},
-			{tableRowSeparator, tableRowSeparator, tableRowSeparator},
-		}
+		pterm.Printf("%s: %s\n", pterm.LightMagenta("TTL"), pterm.Cyan(refresh.TTL("")))
 
 		isAnyStale := false
-		for _, s := range registry.Registry {
-			sMeta, ok := meta.Services[s.Name]
-			if !ok {
-				data = append(data, []string{s.Name, pterm.LightRed("✗ NEVER"), pterm.LightRed("-")})
-				isAnyStale = true
+		for _, c := range contexts {
+			meta, err := cache.ReadMeta(c)
+			if err != nil {
+				logger.Error("Failed to read cache metadata for %s: %v", c, err)
 				continue
 			}
 
-			if sMeta.Refreshing && refresh.IsProcessAlive(sMeta.BusyPID) {
-				data = append(data, []string{s.Name, pterm.LightBlue("● REFRESHING"), pterm.LightBlue(fmt.Sprintf("PID: %d", sMeta.BusyPID))})
-				continue
-			}
-
-			statusText := pterm.LightGreen("✓ FRESH")
-			ageText := pterm.LightGreen("-")
-
-			if !sMeta.LastUpdated.IsZero() {
-				age := time.Since(sMeta.LastUpdated).Truncate(time.Second)
-				ageText = pterm.LightGreen(age.String() + " ago")
-
-				if age > ttl {
-					statusText = pterm.LightYellow("⚠ STALE")
-					ageText = pterm.LightYellow(age.String() + " ago")
-					isAnyStale = true
-				}
-			} else {
-				statusText = pterm.LightYellow("⚠ STALE")
+			isCurrent := c.AccountID == current.AccountID && c.Profile == current.Profile &&
+				(c.Region == current.Region || c.Region == cache.GlobalRegion || slices.Contains(aws.ConfiguredRegions(), c.Region))
+			if stale := renderContextStatus(meta, isCurrent); stale && isCurrent {
 				isAnyStale = true
 			}
-
-			data = append(data, []string{s.Name, statusText, ageText})
 		}
 
-		pterm.DefaultTable.
-			WithBoxed().
-			WithHasHeader().
-			WithHeaderStyle(pterm.NewStyle(pterm.FgLightCyan, pterm.Bold)).
-			WithData(data).
-			Render()
-
 		if isAnyStale && !cacheInitialized {
 			if viper.GetBool("auto-refresh") {
 				pterm.Println()
@@ -132,3 +92,92 @@ Examples:
 		}
 	},
 }
+
+func cacheExists(c cache.Context) bool {
+	_, err := os.Stat(cache.MetaPath(c))
+	return err == nil
+}
+
+// renderContextStatus prints the freshness table of one cache context and
+// reports whether any of its services is stale
+func renderContextStatus(meta cache.Meta, isCurrent bool) bool {
+	title := fmt.Sprintf("%s: %s  %s: %s  %s: %s",
+		pterm.LightMagenta("Account"), pterm.Cyan(meta.Context.AccountID),
+		pterm.LightMagenta("Profile"), pterm.Cyan(meta.Context.Profile),
+		pterm.LightMagenta("Region"), pterm.Cyan(meta.Context.Region))
+	if isCurrent {
+		title += pterm.LightGreen("  (current)")
+	}
+	pterm.Println()
+	pterm.Println(title)
+	pterm.Printf("%s: %s\n\n", pterm.LightMagenta("Last Refresh"), pterm.Cyan(meta.LastUpdated.Format(time.RFC1123)))
+
+	data := pterm.TableData{
+		{"Service", "Status", "Age", "TTL"},
+		{tableRowSeparator, tableRowSeparator, tableRowSeparator, tableRowSeparator},
+	}
+
+	isAnyStale := false
+	var partials []string
+	for _, s := range registry.Registry {
+		// Global services live in the global context only
+		if s.Global != (meta.Context.Region == cache.GlobalRegion) {
+			continue
+		}
+
+		// Disabled services are only shown once refreshed explicitly
+		ttl := refresh.TTL(s.Name)
+		sMeta, ok := meta.Services[s.Name]
+		if !ok && !refresh.Enabled(s.Name) {
+			continue
+		}
+		if !ok {
+			data = append(data, []string{s.Name, pterm.LightRed("✗ NEVER"), pterm.LightRed("-"), ttl.String()})
+			isAnyStale = true
+			continue
+		}
+
+		if sMeta.IsBusy() {
+			data = append(data, []string{s.Name, pterm.LightBlue("● REFRESHING"), pterm.LightBlue(fmt.Sprintf("PID: %d", sMeta.BusyPID)), ttl.String()})
+			continue
+		}
+
+		statusText := pterm.LightGreen("✓ FRESH")
+		ageText := pterm.LightGreen("-")
+		// Partial data is fresh but incomplete, stale data is reported as such
+		if sMeta.IsPartial() {
+			statusText = pterm.LightYellow("◐ PARTIAL")
+			partials = append(partials, fmt.Sprintf("%s: %d failed requests: %s",
+				s.Name, sMeta.ErrorCount, strings.Join(sMeta.Warnings, "; ")))
+		}
+
+		if !sMeta.LastUpdated.IsZero() {
+			age := time.Since(sMeta.LastUpdated).Truncate(time.Second)
+			ageText = pterm.LightGreen(age.String() + " ago")
+
+			if age > ttl {
+				statusText = pterm.LightYellow("⚠ STALE")
+				ageText = pterm.LightYellow(age.String() + " ago")
+				isAnyStale = true
+			}
+		} else {
+			statusText = pterm.LightYellow("⚠ STALE")
+			isAnyStale = true
+		}
+
+		data = append(data, []string{s.Name, statusText, ageText, ttl.String()})
+	}
+
+	pterm.DefaultTable.
+		WithBoxed().
+		WithHasHeader().
+		WithHeaderStyle(pterm.NewStyle(pterm.FgLightCyan, pterm.Bold)).
+		WithData(data).
+		Render()
+
+	for _, p := range partials {
+		pterm.Printf("%s %s\n", pterm.LightYellow("◐"), p)
+	}
+
+	return isAnyStale
+}
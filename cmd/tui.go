@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/sunil-saini/astat/internal/render"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:     "tui",
+	Short:   "Browse cached resources in a full-screen terminal UI",
+	GroupID: "resources",
+	Long: `Browse the cached resources of every enabled service in a full-screen
+terminal UI.
+
+Pick a service, type / to filter its resources fuzzily, move between
+columns with the arrow keys and press s to sort by one. Enter shows
+every field of a resource along with the cached resources related to
+it, which enter jumps to. Press r to refresh the current service, t to
+trace a Route53 record, esc or q to go back and ctrl+c to quit.
+
+Examples:
+  # Browse every enabled service
+  astat tui
+
+  # Browse another profile, showing tag columns
+  astat tui --profile prod --tag-columns team,env`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return render.Browse(cmd.Context())
+	},
+}
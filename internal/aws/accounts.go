@@ -0,0 +1,164 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	orgTypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/viper"
+	"github.com/sunil-saini/astat/internal/cache"
+)
+
+const defaultOrganizationRole = "OrganizationAccountAccessRole"
+
+// Account is an additional AWS account reached by assuming a role with the
+// base credentials
+type Account struct {
+	ID      string `json:"id"`
+	RoleARN string `json:"role_arn"`
+}
+
+// Profile returns the label the account's cached data is stored under
+func (a Account) Profile() string {
+	return "role/" + a.RoleARN[strings.LastIndex(a.RoleARN, "/")+1:]
+}
+
+var (
+	accountsMu sync.Mutex
+	// members holds the organization accounts discovered per base account
+	members = make(map[string][]Account)
+	// assumed shares one credentials cache per role so every service
+	// refreshed in this process reuses the same session
+	assumed = make(map[string]*sdkaws.CredentialsCache)
+)
+
+// ResolveAccounts returns the accounts configured in the accounts section.
+// Organization member accounts found by the last refresh are reused so
+// reading the cache stays offline
+func ResolveAccounts(ctx context.Context, cfg sdkaws.Config, base cache.Context) ([]Account, error) {
+	accounts, err := roleAccounts(base)
+	if err != nil || !viper.GetBool("accounts.organization") {
+		return accounts, err
+	}
+
+	if known, ok := readAccounts()[base.AccountID]; ok {
+		return mergeAccounts(accounts, known, base), nil
+	}
+	return LookupAccounts(ctx, cfg, base)
+}
+
+// LookupAccounts is like ResolveAccounts but discovers the organization
+// member accounts with Organizations ListAccounts (once per process)
+func LookupAccounts(ctx context.Context, cfg sdkaws.Config, base cache.Context) ([]Account, error) {
+	accounts, err := roleAccounts(base)
+	if err != nil || !viper.GetBool("accounts.organization") {
+		return accounts, err
+	}
+
+	accountsMu.Lock()
+	defer accountsMu.Unlock()
+
+	if known, ok := members[base.AccountID]; ok {
+		return mergeAccounts(accounts, known, base), nil
+	}
+
+	roleName := viper.GetString("accounts.role-name")
+	if roleName == "" {
+		roleName = defaultOrganizationRole
+	}
+
+	var discovered []Account
+	paginator := organizations.NewListAccountsPaginator(organizations.NewFromConfig(cfg), &organizations.ListAccountsInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list organization accounts: %w", err)
+		}
+		for _, a := range page.Accounts {
+			if a.State != orgTypes.AccountStateActive {
+				continue
+			}
+			id := sdkaws.ToString(a.Id)
+			discovered = append(discovered, Account{
+				ID:      id,
+				RoleARN: fmt.Sprintf("arn:aws:iam::%s:role/%s", id, roleName),
+			})
+		}
+	}
+	members[base.AccountID] = discovered
+
+	known := readAccounts()
+	known[base.AccountID] = discovered
+	if err := cache.EnsureDir(cache.Dir()); err == nil {
+		_ = cache.Write(cache.Path(cache.Dir(), "accounts"), known)
+	}
+
+	return mergeAccounts(accounts, discovered, base), nil
+}
+
+// AssumeRoleConfig returns a copy of cfg whose credentials come from
+// assuming the account's role with the base credentials
+func AssumeRoleConfig(cfg sdkaws.Config, account Account) sdkaws.Config {
+	accountsMu.Lock()
+	creds, ok := assumed[account.RoleARN]
+	if !ok {
+		sessionName := viper.GetString("accounts.session-name")
+		if sessionName == "" {
+			sessionName = "astat"
+		}
+		externalID := viper.GetString("accounts.external-id")
+
+		provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), account.RoleARN, func(o *stscreds.AssumeRoleOptions) {
+			o.RoleSessionName = sessionName
+			if externalID != "" {
+				o.ExternalID = sdkaws.String(externalID)
+			}
+		})
+		creds = sdkaws.NewCredentialsCache(provider)
+		assumed[account.RoleARN] = creds
+	}
+	accountsMu.Unlock()
+
+	roleCfg := cfg.Copy()
+	roleCfg.Credentials = creds
+	return roleCfg
+}
+
+// roleAccounts parses the role ARNs listed under accounts.roles
+func roleAccounts(base cache.Context) ([]Account, error) {
+	var accounts []Account
+	for _, arn := range viper.GetStringSlice("accounts.roles") {
+		// arn:aws:iam::<account-id>:role/<name>
+		parts := strings.Split(arn, ":")
+		if len(parts) != 6 || parts[2] != "iam" || !strings.HasPrefix(parts[5], "role/") {
+			return nil, fmt.Errorf("invalid role ARN in accounts.roles: %s", arn)
+		}
+		accounts = mergeAccounts(accounts, []Account{{ID: parts[4], RoleARN: arn}}, base)
+	}
+	return accounts, nil
+}
+
+// mergeAccounts appends accounts not already present, skipping the base
+// account whose data the current credentials already cover
+func mergeAccounts(accounts, more []Account, base cache.Context) []Account {
+	for _, a := range more {
+		if a.ID == base.AccountID || slices.ContainsFunc(accounts, func(b Account) bool { return b.ID == a.ID }) {
+			continue
+		}
+		accounts = append(accounts, a)
+	}
+	return accounts
+}
+
+func readAccounts() map[string][]Account {
+	known := make(map[string][]Account)
+	_ = cache.Read(cache.Path(cache.Dir(), "accounts"), &known)
+	return known
+}
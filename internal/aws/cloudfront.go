@@ -2,6 +2,7 @@ package aws
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
@@ -13,10 +14,16 @@ import (
 func FetchCloudFront(ctx context.Context, cfg sdkaws.Config) ([]model.CloudFrontDistribution, error) {
 	client := cloudfront.NewFromConfig(cfg)
 
-	// Fetch all tenants to map domains for multi-tenant distributions
-	tenantsByDist, _ := fetchAllTenants(ctx, client)
+	// Fetch all tenants to map domains for multi-tenant distributions. The
+	// distributions are still worth caching without the tenant domains
+	var errs []error
+	tenantsByDist, err := fetchAllTenants(ctx, client)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("distribution tenants: %w", err))
+	}
 
 	var dists []model.CloudFrontDistribution
+	var arns []string
 	var marker *string
 
 	for {
@@ -30,6 +37,7 @@ func FetchCloudFront(ctx context.Context, cfg sdkaws.Config) ([]model.CloudFront
 		if out.DistributionList != nil {
 			for _, d := range out.DistributionList.Items {
 				dists = append(dists, mapCloudFrontDistribution(d, tenantsByDist))
+				arns = append(arns, sdkaws.ToString(d.ARN))
 			}
 		}
 
@@ -39,7 +47,24 @@ func FetchCloudFront(ctx context.Context, cfg sdkaws.Config) ([]model.CloudFront
 		marker = out.DistributionList.NextMarker
 	}
 
-	return dists, nil
+	// CloudFront is global, its tags are served from us-east-1
+	taggingCfg := cfg.Copy()
+	taggingCfg.Region = "us-east-1"
+	err = addTags(ctx, taggingCfg, "cloudfront:distribution", len(dists),
+		func(i int) string { return arns[i] },
+		func(i int, tags map[string]string) { dists[i].Tags = tags },
+		func(i int) (map[string]string, error) {
+			out, err := client.ListTagsForResource(ctx, &cloudfront.ListTagsForResourceInput{Resource: &arns[i]})
+			if err != nil || out.Tags == nil {
+				return nil, err
+			}
+			return tagsFrom(out.Tags.Items, func(t cfTypes.Tag) (*string, *string) { return t.Key, t.Value }), nil
+		})
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	return dists, partialResult(errs)
 }
 
 func mapCloudFrontDistribution(d cfTypes.DistributionSummary, tenantsByDist map[string][]string) model.CloudFrontDistribution {
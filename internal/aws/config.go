@@ -2,9 +2,11 @@ package aws
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/smithy-go/middleware"
 	"github.com/spf13/viper"
 )
 
@@ -19,5 +21,23 @@ func LoadConfig(ctx context.Context) (aws.Config, error) {
 		opts = append(opts, config.WithRegion(region))
 	}
 
+	// Retries back off on throttling, adaptive mode also slowing down the
+	// client when AWS keeps throttling it
+	if mode := viper.GetString("retry-mode"); mode != "" {
+		retryMode, err := aws.ParseRetryMode(mode)
+		if err != nil {
+			return aws.Config{}, fmt.Errorf("retry-mode: %w", err)
+		}
+		opts = append(opts, config.WithRetryMode(retryMode))
+	}
+
+	if attempts := viper.GetInt("retry-max-attempts"); attempts > 0 {
+		opts = append(opts, config.WithRetryMaxAttempts(attempts))
+	}
+
+	if l := sharedLimiter(); l != nil {
+		opts = append(opts, config.WithAPIOptions([]func(*middleware.Stack) error{rateLimitMiddleware(l)}))
+	}
+
 	return config.LoadDefaultConfig(ctx, opts...)
 }
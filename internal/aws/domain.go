@@ -2,9 +2,11 @@ package aws
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -19,8 +21,38 @@ import (
 
 const fmtNameValue = "%s (%s)"
 
-func TraceDomain(ctx context.Context, cfg sdkaws.Config, domain string) (*model.TraceResult, error) {
-	result := &model.TraceResult{Domain: domain}
+// TraceServices are the cached services a trace resolves its path from
+var TraceServices = []string{"route53-records", "cloudfront", "elb", "rds-instances", "rds-clusters"}
+
+// TraceOptions tune how a domain is traced
+type TraceOptions struct {
+	// Cached resolves the path from the local cache rather than listing
+	// the resources from AWS
+	Cached bool
+	// Offline is set when the cache was asked for explicitly, rather than
+	// picked for being fresh. The live-only data is then left out too: a
+	// domain missing from the cached Route53 records is not resolved with
+	// DNS and target health is marked unavailable
+	Offline bool
+	// Enabled reports whether a service is refreshed, those that are not
+	// being expected to be missing from the cache. All are when nil
+	Enabled func(service string) bool
+}
+
+// tracer holds the state of a trace: the config of the region being looked
+// at, and the result the warnings are added to
+type tracer struct {
+	cfg    sdkaws.Config
+	cached bool
+	// offline leaves out the live-only data too: DNS and target health
+	offline bool
+	opts    TraceOptions
+	result  *model.TraceResult
+}
+
+func TraceDomain(ctx context.Context, cfg sdkaws.Config, domain string, opts TraceOptions) (*model.TraceResult, error) {
+	result := &model.TraceResult{Domain: domain, Cached: opts.Cached}
+	t := &tracer{cfg: cfg, cached: opts.Cached, offline: opts.Cached && opts.Offline, opts: opts, result: result}
 
 	// Normalize input
 	input := strings.TrimSuffix(domain, ".")
@@ -39,12 +71,22 @@ func TraceDomain(ctx context.Context, cfg sdkaws.Config, domain string) (*model.
 	defer cancel()
 
 	// 1. Resolve Route53 Record
-	foundRecord := resolveRoute53Record(ctx, cfg, host)
+	foundRecord := t.resolveRoute53Record(ctx, host)
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 
 	if foundRecord == nil {
+		if t.offline {
+			result.Hops = append(result.Hops, model.TraceNode{
+				Type:   model.NodeDNS,
+				Name:   "External DNS",
+				Value:  "Not in the cached Route53 records, DNS is not resolved offline",
+				Status: model.StatusUnavailable,
+			})
+			return result, nil
+		}
+
 		// Not in Route53
 		hops, err := traceExternalDNS(ctx, host)
 		if err != nil {
@@ -68,22 +110,24 @@ func TraceDomain(ctx context.Context, cfg sdkaws.Config, domain string) (*model.
 	target := strings.TrimSuffix(foundRecord.Value, ".")
 
 	// Update region if target is a regional AWS service (like ELB)
-	if detectedRegion := extractRegion(target); detectedRegion != "" && detectedRegion != cfg.Region {
-		cfg.Region = detectedRegion
+	if detectedRegion := extractRegion(target); detectedRegion != "" && detectedRegion != t.cfg.Region {
+		t.cfg.Region = detectedRegion
 	}
 
 	// 2. Trace CloudFront
-	if node, matched := traceCloudFront(ctx, cfg, target, path); matched {
+	if node, matched, err := t.traceCloudFront(ctx, target, path); matched {
 		r53Node.Children = append(r53Node.Children, *node)
 		result.Hops = append(result.Hops, r53Node)
 		return result, nil
+	} else if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("CloudFront lookup failed: %v", err))
 	}
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 
 	// 3. Trace Load Balancers
-	lbs, _ := FetchLoadBalancers(ctx, cfg)
+	lbs := t.loadBalancers(ctx)
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
@@ -91,7 +135,7 @@ func TraceDomain(ctx context.Context, cfg sdkaws.Config, domain string) (*model.
 	normalizedTarget := strings.TrimPrefix(target, "dualstack.")
 	for _, lb := range lbs {
 		if strings.TrimSuffix(lb.DNSName, ".") == normalizedTarget {
-			lbNode := traceLoadBalancer(ctx, cfg, lb, host, path)
+			lbNode := t.traceLoadBalancer(ctx, lb, host, path)
 			r53Node.Children = append(r53Node.Children, lbNode)
 			result.Hops = append(result.Hops, r53Node)
 			return result, nil
@@ -99,32 +143,84 @@ func TraceDomain(ctx context.Context, cfg sdkaws.Config, domain string) (*model.
 	}
 
 	// 4. Trace RDS
-	if node, matched := traceRDS(ctx, cfg, target); matched {
+	node, matched, err := t.traceRDS(ctx, target)
+	if matched {
 		r53Node.Children = append(r53Node.Children, *node)
 		result.Hops = append(result.Hops, r53Node)
 		return result, nil
 	}
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("RDS lookup failed: %v", err))
+	}
 
 	result.Hops = append(result.Hops, r53Node)
 	return result, nil
 }
 
-func traceRDS(ctx context.Context, cfg sdkaws.Config, target string) (*model.TraceNode, bool) {
-	rdsInstances, _ := FetchRDSInstances(ctx, cfg)
+// loadCached reads the cached data of a service into v, in the context the
+// trace is looking at. In cached mode a refreshed service missing from the
+// cache is reported, since the trace may miss its part of the path
+func (t *tracer) loadCached(ctx context.Context, service string, global bool, v any) bool {
+	if dir, ok := cacheDir(ctx, t.cfg, global); ok {
+		if hit, _ := cache.Load(cache.Path(dir, service), v); hit {
+			return true
+		}
+	}
+	if t.cached && t.enabled(service) {
+		t.result.Warnings = append(t.result.Warnings, fmt.Sprintf("%s is not cached, run 'astat refresh %s'", service, service))
+	}
+	return false
+}
+
+// enabled reports whether a service is refreshed, and so expected in the
+// cache
+func (t *tracer) enabled(service string) bool {
+	return t.opts.Enabled == nil || t.opts.Enabled(service)
+}
+
+// traceRDS looks for the RDS instance or cluster with the target endpoint.
+// The fetch errors are returned so a missing match can be told apart from a
+// failed lookup
+func (t *tracer) traceRDS(ctx context.Context, target string) (*model.TraceNode, bool, error) {
+	if t.cached {
+		var rdsInstances []model.RDSInstance
+		var rdsClusters []model.RDSCluster
+		t.loadCached(ctx, "rds-instances", false, &rdsInstances)
+		t.loadCached(ctx, "rds-clusters", false, &rdsClusters)
+
+		if node, matched := findRDSInstance(target, rdsInstances); matched {
+			return node, true, nil
+		}
+		node, matched := findRDSCluster(target, rdsClusters, rdsInstances)
+		return node, matched, nil
+	}
+
+	rdsInstances, instancesErr := FetchRDSInstances(ctx, t.cfg)
 
 	if node, matched := findRDSInstance(target, rdsInstances); matched {
-		return node, true
+		return node, true, nil
 	}
 
-	rdsClusters, _ := FetchRDSClusters(ctx, cfg)
-	return findRDSCluster(target, rdsClusters, rdsInstances)
+	rdsClusters, clustersErr := FetchRDSClusters(ctx, t.cfg)
+	if node, matched := findRDSCluster(target, rdsClusters, rdsInstances); matched {
+		return node, true, nil
+	}
+
+	var errs []error
+	if instancesErr != nil {
+		errs = append(errs, fmt.Errorf("instances: %w", instancesErr))
+	}
+	if clustersErr != nil {
+		errs = append(errs, fmt.Errorf("clusters: %w", clustersErr))
+	}
+	return nil, false, errors.Join(errs...)
 }
 
 func getHealthStatus(status string) string {
 	if status == "available" || status == "healthy" || status == "InService" {
-		return "healthy"
+		return model.StatusHealthy
 	}
-	return "unhealthy"
+	return model.StatusUnhealthy
 }
 
 func findRDSInstance(target string, instances []model.RDSInstance) (*model.TraceNode, bool) {
@@ -162,7 +258,7 @@ func findRDSCluster(target string, clusters []model.RDSCluster, instances []mode
 			for _, inst := range instances {
 				if inst.ClusterIdentifier == cluster.ClusterIdentifier {
 					clusterNode.Children = append(clusterNode.Children, model.TraceNode{
-						Type:   "RDS Instance",
+						Type:   model.NodeRDSInstance,
 						Name:   fmt.Sprintf(fmtNameValue, inst.InstanceIdentifier, inst.Role),
 						Value:  fmt.Sprintf(fmtNameValue, inst.DBInstanceStatus, inst.InstanceClass),
 						Status: getHealthStatus(inst.DBInstanceStatus),
@@ -175,31 +271,40 @@ func findRDSCluster(target string, clusters []model.RDSCluster, instances []mode
 	return nil, false
 }
 
-func resolveRoute53Record(ctx context.Context, cfg sdkaws.Config, host string) *model.Route53Record {
+func (t *tracer) resolveRoute53Record(ctx context.Context, host string) *model.Route53Record {
+	// Route53 is a global service, cached once per account and profile
+	dir, hasDir := cacheDir(ctx, t.cfg, true)
+
 	// 1. Check Route53 Records Cache
 	var records []model.Route53Record
-	if ok, _ := cache.Load(cache.Path(cache.Dir(), "route53-records"), &records); ok {
+	if t.loadCached(ctx, "route53-records", true, &records) {
 		for _, r := range records {
 			if strings.TrimSuffix(r.Name, ".") == host {
 				return &r
 			}
 		}
 	}
+	if t.cached {
+		return nil
+	}
 
 	// 2. Try to fetch latest Zones from AWS
-	zones, err := FetchHostedZones(ctx, cfg)
-	if err == nil {
+	zones, err := FetchHostedZones(ctx, t.cfg)
+	var partial *PartialError
+	if err == nil || errors.As(err, &partial) {
 		// Update zones cache
-		_ = cache.Write(cache.Path(cache.Dir(), "route53-zones"), zones)
-	} else {
+		if hasDir && cache.EnsureDir(dir) == nil {
+			_ = cache.Write(cache.Path(dir, "route53-zones"), zones)
+		}
+	} else if hasDir {
 		// Fallback to Zones Cache if fetching fails
-		_, _ = cache.Load(cache.Path(cache.Dir(), "route53-zones"), &zones)
+		_, _ = cache.Load(cache.Path(dir, "route53-zones"), &zones)
 	}
 
 	if len(zones) > 0 {
 		matchedZone := findMatchingZone(host, zones)
 		if matchedZone != nil {
-			return fetchRecordInZone(ctx, cfg, host, matchedZone)
+			return fetchRecordInZone(ctx, t.cfg, host, matchedZone)
 		}
 	}
 
@@ -253,8 +358,14 @@ func fetchRecordInZone(ctx context.Context, cfg sdkaws.Config, host string, zone
 	return nil
 }
 
-func traceCloudFront(ctx context.Context, cfg sdkaws.Config, target, path string) (*model.TraceNode, bool) {
-	cfDists, _ := FetchCloudFront(ctx, cfg)
+func (t *tracer) traceCloudFront(ctx context.Context, target, path string) (*model.TraceNode, bool, error) {
+	var cfDists []model.CloudFrontDistribution
+	var err error
+	if t.cached {
+		t.loadCached(ctx, "cloudfront", true, &cfDists)
+	} else {
+		cfDists, err = FetchCloudFront(ctx, t.cfg)
+	}
 	for _, d := range cfDists {
 		if isCloudFrontDistMatch(target, d) {
 			cfNode := model.TraceNode{
@@ -269,10 +380,10 @@ func traceCloudFront(ctx context.Context, cfg sdkaws.Config, target, path string
 				Name:  fmt.Sprintf("Origin (via %s)", matchedPattern),
 				Value: originDomain,
 			})
-			return &cfNode, true
+			return &cfNode, true, nil
 		}
 	}
-	return nil, false
+	return nil, false, err
 }
 
 func isCloudFrontDistMatch(target string, d model.CloudFrontDistribution) bool {
@@ -329,7 +440,7 @@ func matchPath(path, pattern string) bool {
 	return matchPattern(path, pattern)
 }
 
-func traceLoadBalancer(ctx context.Context, cfg sdkaws.Config, lb model.LoadBalancer, host, path string) model.TraceNode {
+func (t *tracer) traceLoadBalancer(ctx context.Context, lb model.LoadBalancer, host, path string) model.TraceNode {
 	nodeType := model.NodeALB
 	switch lb.Type {
 	case "classic":
@@ -345,22 +456,68 @@ func traceLoadBalancer(ctx context.Context, cfg sdkaws.Config, lb model.LoadBala
 		Value: lb.DNSName,
 	}
 
-	ec2Names := getEC2Names()
+	ec2Names := getEC2Names(ctx, t.cfg)
 
 	switch lb.Type {
 	case "classic":
-		return traceClassicLB(ctx, cfg, lbNode, lb, ec2Names)
+		return t.traceClassicLB(ctx, lbNode, lb, ec2Names)
 	case "network":
-		return traceNetworkLB(ctx, cfg, lbNode, lb, ec2Names)
+		return t.traceNetworkLB(ctx, lbNode, lb, ec2Names)
 	default:
-		return traceApplicationLB(ctx, cfg, lbNode, lb, host, path, ec2Names)
+		return t.traceApplicationLB(ctx, lbNode, lb, host, path, ec2Names)
 	}
 }
 
-func getEC2Names() map[string]string {
+// cacheDir returns the cache directory of the context cfg points at, or of
+// its global context for global services
+func cacheDir(ctx context.Context, cfg sdkaws.Config, global bool) (string, bool) {
+	cctx, err := ResolveContext(ctx, cfg)
+	if err != nil {
+		return "", false
+	}
+	if global {
+		cctx = cctx.Global()
+	}
+	return cache.ContextDir(cctx), true
+}
+
+// loadBalancers returns the cached load balancers of the region the trace
+// looks at, along with their listeners and rules, fetching them when not
+// cached
+func (t *tracer) loadBalancers(ctx context.Context) []model.LoadBalancer {
+	var lbs []model.LoadBalancer
+	if t.loadCached(ctx, "elb", false, &lbs) || t.cached {
+		return lbs
+	}
+	lbs, _ = FetchLoadBalancers(ctx, t.cfg)
+	return lbs
+}
+
+// lbListeners returns the listeners of a v2 load balancer, fetching them for
+// load balancers cached before listeners were
+func (t *tracer) lbListeners(ctx context.Context, lb model.LoadBalancer) []model.Listener {
+	if lb.Listeners != nil {
+		return lb.Listeners
+	}
+	if t.cached {
+		if t.enabled("elb") {
+			t.result.Warnings = append(t.result.Warnings, fmt.Sprintf("listeners of %s are not cached, run 'astat refresh elb'", lb.Name))
+		}
+		return nil
+	}
+	listeners, _ := fetchListenerDetails(ctx, t.cfg, lb)
+	return listeners
+}
+
+func getEC2Names(ctx context.Context, cfg sdkaws.Config) map[string]string {
 	ec2Names := make(map[string]string)
+	dir, ok := cacheDir(ctx, cfg, false)
+	if !ok {
+		return ec2Names
+	}
+
 	var instances []model.EC2Instance
-	if ok, _ := cache.Load(cache.Path(cache.Dir(), "ec2"), &instances); ok {
+	if ok, _ := cache.Load(cache.Path(dir, "ec2"), &instances); ok {
 		for _, inst := range instances {
 			name := inst.Name
 			if name == "" {
@@ -372,11 +529,26 @@ func getEC2Names() map[string]string {
 	return ec2Names
 }
 
-func traceClassicLB(ctx context.Context, cfg sdkaws.Config, lbNode model.TraceNode, lb model.LoadBalancer, ec2Names map[string]string) model.TraceNode {
-	listeners, healths, _ := GetClassicLBDetails(ctx, cfg, lb.Name)
-	for _, l := range listeners {
+func (t *tracer) traceClassicLB(ctx context.Context, lbNode model.TraceNode, lb model.LoadBalancer, ec2Names map[string]string) model.TraceNode {
+	var healths []model.InstanceHealth
+	var err error
+	if !t.offline {
+		healths, err = FetchInstanceHealth(ctx, t.cfg, lb.Name)
+		if err != nil {
+			t.result.Warnings = append(t.result.Warnings, fmt.Sprintf("health of load balancer %s failed: %v", lb.Name, err))
+		}
+	}
+	if t.offline || err != nil {
+		// Fall back to just the registered instances if health check fails
+		healths = nil
+		for _, id := range lb.Instances {
+			healths = append(healths, model.InstanceHealth{InstanceID: id})
+		}
+	}
+
+	for _, l := range lb.Listeners {
 		lNode := model.TraceNode{
-			Type: "Listener",
+			Type: model.NodeListener,
 			Name: fmt.Sprintf("%s:%d", l.Protocol, l.Port),
 		}
 		for _, h := range healths {
@@ -388,12 +560,16 @@ func traceClassicLB(ctx context.Context, cfg sdkaws.Config, lbNode model.TraceNo
 			if h.Reason != "" && h.Reason != "N/A" {
 				val += " (" + h.Reason + ")"
 			}
-			status := "unhealthy"
-			if h.State == "InService" {
-				status = "healthy"
+			status := model.StatusUnhealthy
+			switch h.State {
+			case "InService":
+				status = model.StatusHealthy
+			case "":
+				status = model.StatusUnavailable
+				val = "health unavailable"
 			}
 			lNode.Children = append(lNode.Children, model.TraceNode{
-				Type:   "Instance",
+				Type:   model.NodeInstance,
 				Name:   name,
 				Value:  val,
 				Status: status,
@@ -404,16 +580,15 @@ func traceClassicLB(ctx context.Context, cfg sdkaws.Config, lbNode model.TraceNo
 	return lbNode
 }
 
-func traceNetworkLB(ctx context.Context, cfg sdkaws.Config, lbNode model.TraceNode, lb model.LoadBalancer, ec2Names map[string]string) model.TraceNode {
-	listeners, _ := FetchListeners(ctx, cfg, lb.ARN)
-	for _, l := range listeners {
+func (t *tracer) traceNetworkLB(ctx context.Context, lbNode model.TraceNode, lb model.LoadBalancer, ec2Names map[string]string) model.TraceNode {
+	for _, l := range t.lbListeners(ctx, lb) {
 		listenerNode := model.TraceNode{
-			Type: "Listener",
+			Type: model.NodeListener,
 			Name: fmt.Sprintf("%s:%d", l.Protocol, l.Port),
 		}
 		for _, action := range l.DefaultActions {
 			if action.TargetGroupARN != "" {
-				tgNode := traceTargetGroup(ctx, cfg, action.TargetGroupARN, ec2Names)
+				tgNode := t.traceTargetGroup(ctx, action.TargetGroupARN, ec2Names)
 				listenerNode.Children = append(listenerNode.Children, tgNode)
 			}
 		}
@@ -422,23 +597,22 @@ func traceNetworkLB(ctx context.Context, cfg sdkaws.Config, lbNode model.TraceNo
 	return lbNode
 }
 
-func traceApplicationLB(ctx context.Context, cfg sdkaws.Config, lbNode model.TraceNode, lb model.LoadBalancer, host, path string, ec2Names map[string]string) model.TraceNode {
-	listeners, _ := FetchListeners(ctx, cfg, lb.ARN)
-	for _, l := range listeners {
+func (t *tracer) traceApplicationLB(ctx context.Context, lbNode model.TraceNode, lb model.LoadBalancer, host, path string, ec2Names map[string]string) model.TraceNode {
+	for _, l := range t.lbListeners(ctx, lb) {
 		listenerNode := model.TraceNode{
-			Type: "Listener",
+			Type: model.NodeListener,
 			Name: fmt.Sprintf("%s:%d", l.Protocol, l.Port),
 		}
 
-		rules, _ := FetchRules(ctx, cfg, l.ARN)
+		rules := slices.Clone(l.Rules)
 		sortRules(rules)
 
 		if matchedRule := findMatchedALBRule(rules, host, path); matchedRule != nil {
-			listenerNode.Children = append(listenerNode.Children, traceRuleToNode(ctx, cfg, *matchedRule, ec2Names))
+			listenerNode.Children = append(listenerNode.Children, t.traceRuleToNode(ctx, *matchedRule, ec2Names))
 		} else {
 			for _, action := range l.DefaultActions {
 				if action.TargetGroupARN != "" {
-					tgNode := traceTargetGroup(ctx, cfg, action.TargetGroupARN, ec2Names)
+					tgNode := t.traceTargetGroup(ctx, action.TargetGroupARN, ec2Names)
 					tgNode.Name = "[Default] " + tgNode.Name
 					listenerNode.Children = append(listenerNode.Children, tgNode)
 				}
@@ -475,27 +649,27 @@ func matchRuleConditions(conditions []model.Condition, host, path string) bool {
 	return true
 }
 
-func traceRuleToNode(ctx context.Context, cfg sdkaws.Config, rule model.Rule, ec2Names map[string]string) model.TraceNode {
+func (t *tracer) traceRuleToNode(ctx context.Context, rule model.Rule, ec2Names map[string]string) model.TraceNode {
 	condStrings := make([]string, 0, len(rule.Conditions))
 	for _, c := range rule.Conditions {
 		condStrings = append(condStrings, fmt.Sprintf("[%s:%s]", c.Field, strings.Join(c.Values, ",")))
 	}
 
 	ruleNode := model.TraceNode{
-		Type: "Rule",
+		Type: model.NodeRule,
 		Name: fmt.Sprintf("Priority %s: %s", rule.Priority, strings.Join(condStrings, " ")),
 	}
 
 	for _, action := range rule.Actions {
 		if action.TargetGroupARN != "" {
-			tgNode := traceTargetGroup(ctx, cfg, action.TargetGroupARN, ec2Names)
+			tgNode := t.traceTargetGroup(ctx, action.TargetGroupARN, ec2Names)
 			ruleNode.Children = append(ruleNode.Children, tgNode)
 		}
 	}
 	return ruleNode
 }
 
-func traceTargetGroup(ctx context.Context, cfg sdkaws.Config, tgARN string, ec2Names map[string]string) model.TraceNode {
+func (t *tracer) traceTargetGroup(ctx context.Context, tgARN string, ec2Names map[string]string) model.TraceNode {
 	parts := strings.Split(tgARN, ":")
 	tgName := "Unknown"
 	if len(parts) > 5 {
@@ -510,7 +684,22 @@ func traceTargetGroup(ctx context.Context, cfg sdkaws.Config, tgARN string, ec2N
 		Name: tgName,
 	}
 
-	healths, _ := FetchTargetHealth(ctx, cfg, tgARN)
+	// Target health is only known live
+	if t.offline {
+		tgNode.ID = tgARN
+		tgNode.Value = "target health unavailable"
+		tgNode.Status = model.StatusUnavailable
+		return tgNode
+	}
+
+	healths, err := FetchTargetHealth(ctx, t.cfg, tgARN)
+	if err != nil {
+		t.result.Warnings = append(t.result.Warnings, fmt.Sprintf("health of target group %s failed: %v", tgName, err))
+		tgNode.ID = tgARN
+		tgNode.Value = "target health unavailable"
+		tgNode.Status = model.StatusUnavailable
+		return tgNode
+	}
 	hasHealthy := false
 	for _, h := range healths {
 		name := ec2Names[h.InstanceID]
@@ -522,14 +711,14 @@ func traceTargetGroup(ctx context.Context, cfg sdkaws.Config, tgARN string, ec2N
 			val += " (" + h.Reason + ")"
 		}
 
-		status := "unhealthy"
+		status := model.StatusUnhealthy
 		if h.State == "healthy" {
-			status = "healthy"
+			status = model.StatusHealthy
 			hasHealthy = true
 		}
 
 		tgNode.Children = append(tgNode.Children, model.TraceNode{
-			Type:   "Target",
+			Type:   model.NodeTarget,
 			Name:   name,
 			Value:  val,
 			Status: status,
@@ -537,9 +726,9 @@ func traceTargetGroup(ctx context.Context, cfg sdkaws.Config, tgARN string, ec2N
 	}
 
 	if hasHealthy {
-		tgNode.Status = "healthy"
+		tgNode.Status = model.StatusHealthy
 	} else {
-		tgNode.Status = "unhealthy"
+		tgNode.Status = model.StatusUnhealthy
 	}
 
 	return tgNode
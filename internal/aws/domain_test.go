@@ -0,0 +1,65 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/sunil-saini/astat/internal/model"
+)
+
+// failingClient fails every request as a network error would
+type failingClient struct{}
+
+func (failingClient) Do(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+var failingConfig = sdkaws.Config{
+	Region:           "us-east-1",
+	Credentials:      sdkaws.AnonymousCredentials{},
+	HTTPClient:       failingClient{},
+	RetryMaxAttempts: 1,
+}
+
+func TestTraceTargetGroupHealthError(t *testing.T) {
+	// Health is fetched live when the path comes from a fresh cache
+	result := &model.TraceResult{Domain: "example.com"}
+	tr := &tracer{cfg: failingConfig, cached: true, result: result}
+
+	arn := "arn:aws:elasticloadbalancing:us-east-1:123456789012:targetgroup/web/0123456789abcdef"
+	node := tr.traceTargetGroup(context.Background(), arn, nil)
+
+	if node.Status != model.StatusUnavailable {
+		t.Errorf("status = %q, want %q", node.Status, model.StatusUnavailable)
+	}
+	if node.Name != "web" || node.ID != arn {
+		t.Errorf("node = %+v, want target group web identified by its ARN", node)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "web") {
+		t.Errorf("warnings = %q, want one naming the target group", result.Warnings)
+	}
+
+	result.Hops = []model.TraceNode{node}
+	if unhealthy := result.Unhealthy(); len(unhealthy) != 0 {
+		t.Errorf("Unhealthy() = %+v, want none for an unknown health", unhealthy)
+	}
+}
+
+func TestTraceTargetGroupOffline(t *testing.T) {
+	result := &model.TraceResult{Domain: "example.com"}
+	tr := &tracer{cfg: failingConfig, cached: true, offline: true, result: result}
+
+	arn := "arn:aws:elasticloadbalancing:us-east-1:123456789012:targetgroup/web/0123456789abcdef"
+	node := tr.traceTargetGroup(context.Background(), arn, nil)
+
+	if node.Status != model.StatusUnavailable {
+		t.Errorf("status = %q, want %q", node.Status, model.StatusUnavailable)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("warnings = %q, want none as health is not looked up offline", result.Warnings)
+	}
+}
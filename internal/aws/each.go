@@ -0,0 +1,29 @@
+package aws
+
+import (
+	"context"
+	"sync"
+)
+
+// fetchConcurrency bounds the requests made at once for the APIs describing
+// one resource per call, e.g. the tags of a function or the listeners of a
+// load balancer
+const fetchConcurrency = 10
+
+// fetchEach calls fetch for each of the n resources with bounded
+// concurrency, stopping early when ctx is done
+func fetchEach(ctx context.Context, n int, fetch func(i int)) {
+	sem := make(chan struct{}, fetchConcurrency)
+	var wg sync.WaitGroup
+	for i := range n {
+		if ctx.Err() != nil {
+			break
+		}
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			fetch(i)
+		})
+	}
+	wg.Wait()
+}
@@ -2,6 +2,7 @@ package aws
 
 import (
 	"context"
+	"strings"
 
 	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
@@ -10,19 +11,48 @@ import (
 )
 
 func FetchEC2Instances(ctx context.Context, cfg sdkaws.Config) ([]model.EC2Instance, error) {
+	return describeEC2Instances(ctx, ec2.NewFromConfig(cfg), &ec2.DescribeInstancesInput{})
+}
+
+// DescribeEC2Instances fetches the instances with the given ID, or Name tag
+func DescribeEC2Instances(ctx context.Context, cfg sdkaws.Config, id string) ([]model.EC2Instance, error) {
 	client := ec2.NewFromConfig(cfg)
 
-	out, err := client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{})
-	if err != nil {
-		return nil, err
+	input := &ec2.DescribeInstancesInput{}
+	if strings.HasPrefix(id, "i-") {
+		input.InstanceIds = []string{id}
+	} else {
+		input.Filters = []ec2Types.Filter{{Name: sdkaws.String("tag:Name"), Values: []string{id}}}
 	}
 
+	instances, err := describeEC2Instances(ctx, client, input)
+	if err != nil && isNotFound(err) {
+		return nil, nil
+	}
+	return instances, err
+}
+
+// describeEC2Instances pages through the instances matching input, reporting
+// the running count as progress after each page
+func describeEC2Instances(ctx context.Context, client *ec2.Client, input *ec2.DescribeInstancesInput) ([]model.EC2Instance, error) {
+	paginator := ec2.NewDescribeInstancesPaginator(client, input)
+
 	var instances []model.EC2Instance
+	for paginator.HasMorePages() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
 
-	for _, res := range out.Reservations {
-		for _, inst := range res.Instances {
-			instances = append(instances, mapEC2Instance(inst))
+		for _, res := range page.Reservations {
+			for _, inst := range res.Instances {
+				instances = append(instances, mapEC2Instance(inst))
+			}
 		}
+		reportProgress(ctx, len(instances), "instances")
 	}
 
 	return instances, nil
@@ -30,10 +60,12 @@ func FetchEC2Instances(ctx context.Context, cfg sdkaws.Config) ([]model.EC2Insta
 
 func mapEC2Instance(inst ec2Types.Instance) model.EC2Instance {
 	name := ""
+	tags := make(map[string]string, len(inst.Tags))
 	for _, tag := range inst.Tags {
-		if tag.Key != nil && *tag.Key == "Name" {
-			name = *tag.Value
-			break
+		key, value := sdkaws.ToString(tag.Key), sdkaws.ToString(tag.Value)
+		tags[key] = value
+		if key == "Name" {
+			name = value
 		}
 	}
 
@@ -47,14 +79,53 @@ func mapEC2Instance(inst ec2Types.Instance) model.EC2Instance {
 		publicIP = *inst.PublicIpAddress
 	}
 
+	var groups, groupNames []string
+	for _, sg := range inst.SecurityGroups {
+		groups = append(groups, sdkaws.ToString(sg.GroupId))
+		groupNames = append(groupNames, sdkaws.ToString(sg.GroupName))
+	}
+
+	var enis []string
+	for _, eni := range inst.NetworkInterfaces {
+		enis = append(enis, sdkaws.ToString(eni.NetworkInterfaceId))
+	}
+
+	profile := ""
+	if inst.IamInstanceProfile != nil {
+		arn := sdkaws.ToString(inst.IamInstanceProfile.Arn)
+		profile = arn[strings.LastIndex(arn, "/")+1:]
+	}
+
+	platform := sdkaws.ToString(inst.PlatformDetails)
+	if platform == "" {
+		platform = string(inst.Platform)
+	}
+
+	lifecycle := string(inst.InstanceLifecycle)
+	if lifecycle == "" {
+		lifecycle = "on-demand"
+	}
+
 	return model.EC2Instance{
-		InstanceID:   *inst.InstanceId,
-		Name:         name,
-		State:        string(inst.State.Name),
-		InstanceType: string(inst.InstanceType),
-		AZ:           *inst.Placement.AvailabilityZone,
-		PrivateIP:    privateIP,
-		PublicIP:     publicIP,
-		LaunchTime:   inst.LaunchTime.Format("2006-01-02 15:04:05"),
+		InstanceID:         *inst.InstanceId,
+		Name:               name,
+		State:              string(inst.State.Name),
+		InstanceType:       string(inst.InstanceType),
+		AZ:                 *inst.Placement.AvailabilityZone,
+		PrivateIP:          privateIP,
+		PublicIP:           publicIP,
+		LaunchTime:         inst.LaunchTime.Format("2006-01-02 15:04:05"),
+		VPCID:              sdkaws.ToString(inst.VpcId),
+		SubnetID:           sdkaws.ToString(inst.SubnetId),
+		SecurityGroups:     groups,
+		SecurityGroupNames: groupNames,
+		IAMProfile:         profile,
+		ImageID:            sdkaws.ToString(inst.ImageId),
+		KeyName:            sdkaws.ToString(inst.KeyName),
+		Platform:           platform,
+		Architecture:       string(inst.Architecture),
+		Lifecycle:          lifecycle,
+		ENIs:               enis,
+		Tags:               tags,
 	}
 }
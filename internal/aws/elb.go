@@ -2,139 +2,359 @@ package aws
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
 	elb "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing"
+	elbTypes "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing/types"
 	elbv2 "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
 	elbv2Types "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
 	"github.com/sunil-saini/astat/internal/model"
 )
 
+// FetchLoadBalancers fetches the classic and v2 load balancers along with
+// their listeners, and the rules of application load balancers. Failing
+// requests for one kind or for some listeners give a PartialError
 func FetchLoadBalancers(ctx context.Context, cfg sdkaws.Config) ([]model.LoadBalancer, error) {
-	var lbs []model.LoadBalancer
-	var mu sync.Mutex
+	var classic, v2 []model.LoadBalancer
+	var classicErr, v2Err error
 	var wg sync.WaitGroup
 
-	// Fetch v1 Load Balancers (Classic)
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		clientV1 := elb.NewFromConfig(cfg)
-		if out, err := clientV1.DescribeLoadBalancers(ctx, &elb.DescribeLoadBalancersInput{}); err == nil {
+	wg.Go(func() { classic, classicErr = fetchClassicLoadBalancers(ctx, cfg) })
+	wg.Go(func() { v2, v2Err = fetchLoadBalancersV2(ctx, cfg) })
+	wg.Wait()
+
+	if classicErr != nil && v2Err != nil {
+		return nil, errors.Join(classicErr, v2Err)
+	}
+
+	var errs []error
+	if classicErr != nil {
+		errs = append(errs, fmt.Errorf("classic load balancers: %w", classicErr))
+	}
+	if v2Err != nil {
+		errs = append(errs, fmt.Errorf("load balancers: %w", v2Err))
+	}
+
+	lbs := append(classic, v2...)
+	errs = append(errs, addListeners(ctx, cfg, lbs)...)
+	errs = append(errs, addLoadBalancerTags(ctx, cfg, lbs)...)
+	return lbs, partialResult(errs)
+}
+
+func fetchClassicLoadBalancers(ctx context.Context, cfg sdkaws.Config) ([]model.LoadBalancer, error) {
+	paginator := elb.NewDescribeLoadBalancersPaginator(elb.NewFromConfig(cfg), &elb.DescribeLoadBalancersInput{})
+
+	var lbs []model.LoadBalancer
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, lb := range page.LoadBalancerDescriptions {
+			lbs = append(lbs, mapClassicLoadBalancer(lb))
+		}
+	}
+	return lbs, nil
+}
+
+func fetchLoadBalancersV2(ctx context.Context, cfg sdkaws.Config) ([]model.LoadBalancer, error) {
+	paginator := elbv2.NewDescribeLoadBalancersPaginator(elbv2.NewFromConfig(cfg), &elbv2.DescribeLoadBalancersInput{})
+
+	var lbs []model.LoadBalancer
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, lb := range page.LoadBalancers {
+			lbs = append(lbs, mapLoadBalancer(lb))
+		}
+	}
+	return lbs, nil
+}
+
+// addListeners fetches the listeners of the v2 load balancers, classic ones
+// coming with theirs, and returns the errors of the failed load balancers
+func addListeners(ctx context.Context, cfg sdkaws.Config, lbs []model.LoadBalancer) []error {
+	var mu sync.Mutex
+	var errs []error
+	fetchEach(ctx, len(lbs), func(i int) {
+		if lbs[i].ARN == "" {
+			return
+		}
+		listeners, err := fetchListenerDetails(ctx, cfg, lbs[i])
+		if err != nil {
 			mu.Lock()
+			errs = append(errs, fmt.Errorf("listeners of %s: %w", lbs[i].Name, err))
+			mu.Unlock()
+			return
+		}
+		lbs[i].Listeners = listeners
+	})
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// fetchListenerDetails fetches the listeners of a v2 load balancer, with the
+// rules of each for application load balancers
+func fetchListenerDetails(ctx context.Context, cfg sdkaws.Config, lb model.LoadBalancer) ([]model.Listener, error) {
+	listeners, err := FetchListeners(ctx, cfg, lb.ARN)
+	if err != nil || lb.Type != string(elbv2Types.LoadBalancerTypeEnumApplication) {
+		return listeners, err
+	}
+
+	for i := range listeners {
+		rules, err := FetchRules(ctx, cfg, listeners[i].ARN)
+		if err != nil {
+			return nil, err
+		}
+		listeners[i].Rules = rules
+	}
+	return listeners, nil
+}
+
+// DescribeLoadBalancer fetches the load balancers, of any type, with the
+// given name or ARN
+func DescribeLoadBalancer(ctx context.Context, cfg sdkaws.Config, id string) ([]model.LoadBalancer, error) {
+	var lbs []model.LoadBalancer
+
+	input := &elbv2.DescribeLoadBalancersInput{Names: []string{id}}
+	if strings.HasPrefix(id, "arn:") {
+		input = &elbv2.DescribeLoadBalancersInput{LoadBalancerArns: []string{id}}
+	}
+	out, err := elbv2.NewFromConfig(cfg).DescribeLoadBalancers(ctx, input)
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+	if err == nil {
+		for _, lb := range out.LoadBalancers {
+			lbs = append(lbs, mapLoadBalancer(lb))
+		}
+	}
+
+	if !strings.HasPrefix(id, "arn:") {
+		out, err := elb.NewFromConfig(cfg).DescribeLoadBalancers(ctx, &elb.DescribeLoadBalancersInput{
+			LoadBalancerNames: []string{id},
+		})
+		if err != nil && !isNotFound(err) {
+			return nil, err
+		}
+		if err == nil {
 			for _, lb := range out.LoadBalancerDescriptions {
-				lbs = append(lbs, model.LoadBalancer{
-					Type:      "classic",
-					Name:      *lb.LoadBalancerName,
-					Scheme:    *lb.Scheme,
-					CreatedAt: lb.CreatedTime.Format(time.RFC3339),
-					DNSName:   *lb.DNSName,
-				})
+				lbs = append(lbs, mapClassicLoadBalancer(lb))
 			}
-			mu.Unlock()
 		}
-	}()
-
-	// Fetch v2 Load Balancers (ALB/NLB)
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		clientV2 := elbv2.NewFromConfig(cfg)
-		if out, err := clientV2.DescribeLoadBalancers(ctx, &elbv2.DescribeLoadBalancersInput{}); err == nil {
-			mu.Lock()
-			for _, lb := range out.LoadBalancers {
-				lbs = append(lbs, model.LoadBalancer{
-					Type:      string(lb.Type),
-					Name:      *lb.LoadBalancerName,
-					DNSName:   *lb.DNSName,
-					Scheme:    string(lb.Scheme),
-					CreatedAt: lb.CreatedTime.Format(time.RFC3339),
-					ARN:       *lb.LoadBalancerArn,
-				})
+	}
+	errs := addListeners(ctx, cfg, lbs)
+	errs = append(errs, addLoadBalancerTags(ctx, cfg, lbs)...)
+	return lbs, partialResult(errs)
+}
+
+// addLoadBalancerTags fetches the tags of the load balancers, 20 per request,
+// by ARN for v2 and by name for classic ones, and returns the errors of the
+// failed requests
+func addLoadBalancerTags(ctx context.Context, cfg sdkaws.Config, lbs []model.LoadBalancer) []error {
+	byKey := make(map[string]*model.LoadBalancer, len(lbs))
+	var arns, names []string
+	for i := range lbs {
+		if lbs[i].ARN != "" {
+			arns = append(arns, lbs[i].ARN)
+			byKey[lbs[i].ARN] = &lbs[i]
+		} else {
+			names = append(names, lbs[i].Name)
+			byKey[lbs[i].Name] = &lbs[i]
+		}
+	}
+
+	var errs []error
+	tags, err := fetchTagsV2(ctx, cfg, arns)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("load balancer %w", err))
+	}
+	for arn, t := range tags {
+		byKey[arn].Tags = t
+	}
+
+	clientV1 := elb.NewFromConfig(cfg)
+	failed := &tagErrors{}
+	for batch := range slices.Chunk(names, 20) {
+		out, err := clientV1.DescribeTags(ctx, &elb.DescribeTagsInput{LoadBalancerNames: batch})
+		if err != nil {
+			for range batch {
+				failed.add(err)
 			}
-			mu.Unlock()
+			continue
 		}
-	}()
+		for _, d := range out.TagDescriptions {
+			if lb, ok := byKey[sdkaws.ToString(d.LoadBalancerName)]; ok {
+				lb.Tags = tagsFrom(d.Tags, func(t elbTypes.Tag) (*string, *string) { return t.Key, t.Value })
+			}
+		}
+	}
+	if err := failed.err("classic load balancers", len(names)); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
 
-	wg.Wait()
-	return lbs, nil
+func mapClassicLoadBalancer(lb elbTypes.LoadBalancerDescription) model.LoadBalancer {
+	var listeners []model.Listener
+	for _, l := range lb.ListenerDescriptions {
+		if l.Listener == nil {
+			continue
+		}
+		listeners = append(listeners, model.Listener{
+			Protocol: sdkaws.ToString(l.Listener.Protocol),
+			Port:     l.Listener.LoadBalancerPort,
+		})
+	}
+
+	var instances []string
+	for _, inst := range lb.Instances {
+		instances = append(instances, sdkaws.ToString(inst.InstanceId))
+	}
+
+	return model.LoadBalancer{
+		Type:      "classic",
+		Name:      *lb.LoadBalancerName,
+		Scheme:    *lb.Scheme,
+		CreatedAt: lb.CreatedTime.Format(time.RFC3339),
+		DNSName:   *lb.DNSName,
+		Listeners: listeners,
+		Instances: instances,
+	}
+}
+
+func mapLoadBalancer(lb elbv2Types.LoadBalancer) model.LoadBalancer {
+	return model.LoadBalancer{
+		Type:      string(lb.Type),
+		Name:      *lb.LoadBalancerName,
+		DNSName:   *lb.DNSName,
+		Scheme:    string(lb.Scheme),
+		CreatedAt: lb.CreatedTime.Format(time.RFC3339),
+		ARN:       *lb.LoadBalancerArn,
+	}
 }
 
-func FetchTargetGroups(ctx context.Context, cfg sdkaws.Config, lbARN *string) ([]model.TargetGroup, error) {
+// fetchTagsV2 fetches the tags of v2 load balancers or target groups, 20
+// resources per request, by ARN. The tags of the other batches are still
+// returned when some fail
+func fetchTagsV2(ctx context.Context, cfg sdkaws.Config, arns []string) (map[string]map[string]string, error) {
 	client := elbv2.NewFromConfig(cfg)
-	out, err := client.DescribeTargetGroups(ctx, &elbv2.DescribeTargetGroupsInput{
-		LoadBalancerArn: lbARN,
-	})
-	if err != nil {
-		return nil, err
+	tags := make(map[string]map[string]string, len(arns))
+	failed := &tagErrors{}
+	for batch := range slices.Chunk(arns, 20) {
+		out, err := client.DescribeTags(ctx, &elbv2.DescribeTagsInput{ResourceArns: batch})
+		if err != nil {
+			for range batch {
+				failed.add(err)
+			}
+			continue
+		}
+		for _, d := range out.TagDescriptions {
+			tags[sdkaws.ToString(d.ResourceArn)] = tagsFrom(d.Tags, func(t elbv2Types.Tag) (*string, *string) { return t.Key, t.Value })
+		}
 	}
+	return tags, failed.err("resources", len(arns))
+}
+
+// FetchTargetGroups fetches every target group of the region
+func FetchTargetGroups(ctx context.Context, cfg sdkaws.Config) ([]model.TargetGroup, error) {
+	paginator := elbv2.NewDescribeTargetGroupsPaginator(elbv2.NewFromConfig(cfg), &elbv2.DescribeTargetGroupsInput{})
 
 	var tgs []model.TargetGroup
-	for _, tg := range out.TargetGroups {
-		tgs = append(tgs, model.TargetGroup{
-			Name:       *tg.TargetGroupName,
-			Protocol:   string(tg.Protocol),
-			Port:       *tg.Port,
-			TargetType: string(tg.TargetType),
-			ARN:        *tg.TargetGroupArn,
-		})
+	var arns []string
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, tg := range page.TargetGroups {
+			tgs = append(tgs, model.TargetGroup{
+				Name:             sdkaws.ToString(tg.TargetGroupName),
+				Protocol:         string(tg.Protocol),
+				Port:             sdkaws.ToInt32(tg.Port),
+				TargetType:       string(tg.TargetType),
+				VPCID:            sdkaws.ToString(tg.VpcId),
+				LoadBalancerARNs: tg.LoadBalancerArns,
+				ARN:              sdkaws.ToString(tg.TargetGroupArn),
+			})
+			arns = append(arns, sdkaws.ToString(tg.TargetGroupArn))
+		}
+	}
+
+	tags, err := fetchTagsV2(ctx, cfg, arns)
+	for i := range tgs {
+		tgs[i].Tags = tags[tgs[i].ARN]
+	}
+	if err != nil {
+		return tgs, partialResult([]error{fmt.Errorf("target group %w", err)})
 	}
 	return tgs, nil
 }
 
 func FetchListeners(ctx context.Context, cfg sdkaws.Config, lbARN string) ([]model.Listener, error) {
-	client := elbv2.NewFromConfig(cfg)
-	out, err := client.DescribeListeners(ctx, &elbv2.DescribeListenersInput{
+	paginator := elbv2.NewDescribeListenersPaginator(elbv2.NewFromConfig(cfg), &elbv2.DescribeListenersInput{
 		LoadBalancerArn: &lbARN,
 	})
-	if err != nil {
-		return nil, err
-	}
 
 	var listeners []model.Listener
-	for _, l := range out.Listeners {
-		var actions []model.Action
-		for _, a := range l.DefaultActions {
-			tgArn := ""
-			if a.TargetGroupArn != nil {
-				tgArn = *a.TargetGroupArn
-			}
-			actions = append(actions, model.Action{
-				Type:           string(a.Type),
-				TargetGroupARN: tgArn,
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range page.Listeners {
+			listeners = append(listeners, model.Listener{
+				ARN:            *l.ListenerArn,
+				Protocol:       string(l.Protocol),
+				Port:           sdkaws.ToInt32(l.Port),
+				DefaultActions: mapRuleActions(l.DefaultActions),
 			})
 		}
-		listeners = append(listeners, model.Listener{
-			ARN:            *l.ListenerArn,
-			Protocol:       string(l.Protocol),
-			Port:           *l.Port,
-			DefaultActions: actions,
-		})
 	}
 	return listeners, nil
 }
 
 func FetchRules(ctx context.Context, cfg sdkaws.Config, listenerARN string) ([]model.Rule, error) {
 	client := elbv2.NewFromConfig(cfg)
-	out, err := client.DescribeRules(ctx, &elbv2.DescribeRulesInput{
-		ListenerArn: &listenerARN,
-	})
-	if err != nil {
-		return nil, err
-	}
 
 	var rules []model.Rule
-	for _, r := range out.Rules {
-		rules = append(rules, model.Rule{
-			ARN:        *r.RuleArn,
-			Priority:   *r.Priority,
-			IsDefault:  *r.IsDefault,
-			Conditions: mapRuleConditions(r.Conditions),
-			Actions:    mapRuleActions(r.Actions),
+	var marker *string
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		out, err := client.DescribeRules(ctx, &elbv2.DescribeRulesInput{
+			ListenerArn: &listenerARN,
+			Marker:      marker,
 		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range out.Rules {
+			rules = append(rules, model.Rule{
+				ARN:        *r.RuleArn,
+				Priority:   *r.Priority,
+				IsDefault:  *r.IsDefault,
+				Conditions: mapRuleConditions(r.Conditions),
+				Actions:    mapRuleActions(r.Actions),
+			})
+		}
+
+		if out.NextMarker == nil || *out.NextMarker == "" {
+			break
+		}
+		marker = out.NextMarker
 	}
 	return rules, nil
 }
@@ -206,62 +426,23 @@ func FetchTargetHealth(ctx context.Context, cfg sdkaws.Config, tgARN string) ([]
 	return health, nil
 }
 
-func GetClassicLBDetails(ctx context.Context, cfg sdkaws.Config, lbName string) ([]model.Listener, []model.InstanceHealth, error) {
-	client := elb.NewFromConfig(cfg)
-	out, err := client.DescribeLoadBalancers(ctx, &elb.DescribeLoadBalancersInput{
-		LoadBalancerNames: []string{lbName},
+// FetchInstanceHealth fetches the health of the instances registered with a
+// classic load balancer
+func FetchInstanceHealth(ctx context.Context, cfg sdkaws.Config, lbName string) ([]model.InstanceHealth, error) {
+	out, err := elb.NewFromConfig(cfg).DescribeInstanceHealth(ctx, &elb.DescribeInstanceHealthInput{
+		LoadBalancerName: &lbName,
 	})
 	if err != nil {
-		return nil, nil, err
-	}
-
-	if len(out.LoadBalancerDescriptions) == 0 {
-		return nil, nil, fmt.Errorf("LB not found")
-	}
-
-	lb := out.LoadBalancerDescriptions[0]
-	var listeners []model.Listener
-	for _, l := range lb.ListenerDescriptions {
-		protocol := ""
-		if l.Listener.Protocol != nil {
-			protocol = *l.Listener.Protocol
-		}
-		listeners = append(listeners, model.Listener{
-			Protocol: protocol,
-			Port:     int32(l.Listener.LoadBalancerPort),
-		})
+		return nil, err
 	}
 
 	var healths []model.InstanceHealth
-	hOut, err := client.DescribeInstanceHealth(ctx, &elb.DescribeInstanceHealthInput{
-		LoadBalancerName: &lbName,
-	})
-	if err == nil {
-		for _, s := range hOut.InstanceStates {
-			state := ""
-			if s.State != nil {
-				state = *s.State
-			}
-			reason := ""
-			if s.Description != nil {
-				reason = *s.Description
-			}
-			healths = append(healths, model.InstanceHealth{
-				InstanceID: *s.InstanceId,
-				State:      state,
-				Reason:     reason,
-			})
-		}
-
-		return listeners, healths, nil
-	}
-
-	// Fallback to just IDs if health check fails
-	for _, inst := range lb.Instances {
+	for _, s := range out.InstanceStates {
 		healths = append(healths, model.InstanceHealth{
-			InstanceID: *inst.InstanceId,
+			InstanceID: sdkaws.ToString(s.InstanceId),
+			State:      sdkaws.ToString(s.State),
+			Reason:     sdkaws.ToString(s.Description),
 		})
 	}
-
-	return listeners, healths, nil
+	return healths, nil
 }
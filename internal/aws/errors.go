@@ -0,0 +1,61 @@
+package aws
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/aws/smithy-go"
+)
+
+// notFoundCodes are parts of the error codes AWS APIs answer with when the
+// requested resource does not exist, or its identifier cannot exist
+var notFoundCodes = []string{"NotFound", "NoSuch", "DoesNotExist", "NonExistent", "Malformed"}
+
+// isNotFound reports whether err tells that a looked up resource does not
+// exist
+func isNotFound(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	code := apiErr.ErrorCode()
+	for _, c := range notFoundCodes {
+		if strings.Contains(code, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// PartialError is returned along with the data a fetch collected when some
+// of its requests failed. The data is incomplete but still worth caching
+type PartialError struct {
+	Errs []error
+}
+
+func (e *PartialError) Error() string {
+	return "partial results: " + strings.Join(e.Warnings(), "; ")
+}
+
+// Warnings returns the distinct messages of the errors, in order
+func (e *PartialError) Warnings() []string {
+	var msgs []string
+	for _, err := range e.Errs {
+		if msg := err.Error(); !slices.Contains(msgs, msg) {
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
+
+func (e *PartialError) Unwrap() []error { return e.Errs }
+
+// partialResult returns a PartialError for errs, or nil when there are none
+func partialResult(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &PartialError{Errs: errs}
+}
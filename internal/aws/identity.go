@@ -0,0 +1,188 @@
+package aws
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/viper"
+	"github.com/sunil-saini/astat/internal/cache"
+)
+
+const (
+	// identityTTL is how long an account ID recorded for a profile is
+	// trusted before being confirmed with STS again
+	identityTTL = 24 * time.Hour
+	// identityTimeout bounds the confirmation of an expired account, and
+	// identityRetry spaces out failed ones, so reading the cache offline is
+	// not held up
+	identityTimeout = 2 * time.Second
+	identityRetry   = time.Hour
+)
+
+// identity is the account the credentials of a profile were found to belong
+// to, and when
+type identity struct {
+	Account string `json:"account"`
+	// Source fingerprints the credential settings of the profile, the
+	// account having to be looked up again once they change
+	Source   string    `json:"source"`
+	Verified time.Time `json:"verified"`
+	// Checked is when confirming the account last failed
+	Checked time.Time `json:"checked,omitzero"`
+}
+
+// UnmarshalJSON also reads the bare account IDs recorded by older versions,
+// as identities of unknown source due for confirmation
+func (id *identity) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &id.Account); err == nil {
+		return nil
+	}
+	type plain identity
+	return json.Unmarshal(data, (*plain)(id))
+}
+
+var (
+	identityMu sync.Mutex
+	// verified holds the account IDs confirmed by STS during this process
+	verified = make(map[string]string)
+)
+
+// ProfileName returns the label used to key cached data for the active credentials
+func ProfileName() string {
+	if p := viper.GetString("profile"); p != "" {
+		return p
+	}
+	if p := os.Getenv("AWS_PROFILE"); p != "" {
+		return p
+	}
+	if os.Getenv("AWS_ACCESS_KEY_ID") != "" {
+		return "env"
+	}
+	return "default"
+}
+
+// ResolveContext returns the cache context for cfg. The account ID is taken
+// from the local identity cache when known, so listing cached data never
+// needs a network call; otherwise it is looked up with STS. A recorded
+// account is looked up again when the profile's credential settings changed,
+// and confirmed once older than identityTTL, kept when STS cannot be reached
+func ResolveContext(ctx context.Context, cfg sdkaws.Config) (cache.Context, error) {
+	profile := ProfileName()
+
+	identityMu.Lock()
+	account, ok := verified[identityKey(profile)]
+	identityMu.Unlock()
+
+	if !ok {
+		id, found := readIdentities()[identityKey(profile)]
+		sourceChanged := !id.Verified.IsZero() && id.Source != credentialSource(ctx, profile)
+		if !found || id.Account == "" || sourceChanged {
+			return LookupContext(ctx, cfg)
+		}
+		if time.Since(id.Verified) > identityTTL && time.Since(id.Checked) > identityRetry {
+			verifyCtx, cancel := context.WithTimeout(ctx, identityTimeout)
+			cctx, err := LookupContext(verifyCtx, cfg)
+			cancel()
+			if err == nil {
+				return cctx, nil
+			}
+			id.Checked = time.Now()
+			writeIdentity(identityKey(profile), id)
+		}
+		account = id.Account
+	}
+
+	return cache.Context{AccountID: account, Profile: profile, Region: cfg.Region}, nil
+}
+
+// LookupContext resolves the account ID with STS GetCallerIdentity (once per
+// process and profile) and records it in the local identity cache
+func LookupContext(ctx context.Context, cfg sdkaws.Config) (cache.Context, error) {
+	profile := ProfileName()
+	key := identityKey(profile)
+
+	identityMu.Lock()
+	defer identityMu.Unlock()
+
+	account, ok := verified[key]
+	if !ok {
+		out, err := sts.NewFromConfig(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+		if err != nil {
+			return cache.Context{}, fmt.Errorf("failed to resolve AWS account: %w", err)
+		}
+		account = sdkaws.ToString(out.Account)
+		verified[key] = account
+
+		writeIdentity(key, identity{Account: account, Source: credentialSource(ctx, profile), Verified: time.Now()})
+	}
+
+	return cache.Context{AccountID: account, Profile: profile, Region: cfg.Region}, nil
+}
+
+// identityKey distinguishes static environment credentials by access key so
+// that switching keys never serves another account's cache
+func identityKey(profile string) string {
+	if profile == "env" {
+		return "env:" + os.Getenv("AWS_ACCESS_KEY_ID")
+	}
+	return profile
+}
+
+// writeIdentity records the identity of a profile in the identity cache
+func writeIdentity(key string, id identity) {
+	identities := readIdentities()
+	identities[key] = id
+	if err := cache.EnsureDir(cache.Dir()); err == nil {
+		_ = cache.Write(cache.Path(cache.Dir(), "identities"), identities)
+	}
+}
+
+func readIdentities() map[string]identity {
+	identities := make(map[string]identity)
+	_ = cache.Read(cache.Path(cache.Dir(), "identities"), &identities)
+	return identities
+}
+
+// credentialSource fingerprints the settings of a profile that decide which
+// account its credentials belong to. Environment credentials are told apart
+// by their access key already
+func credentialSource(ctx context.Context, profile string) string {
+	if profile == "env" {
+		return ""
+	}
+	// The files are looked up like the credentials are, honouring the
+	// environment variables LoadSharedConfigProfile leaves out
+	sc, err := config.LoadSharedConfigProfile(ctx, profile, func(o *config.LoadSharedConfigOptions) {
+		if f := os.Getenv("AWS_CONFIG_FILE"); f != "" {
+			o.ConfigFiles = []string{f}
+		}
+		if f := os.Getenv("AWS_SHARED_CREDENTIALS_FILE"); f != "" {
+			o.CredentialsFiles = []string{f}
+		}
+	})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		sc.Credentials.AccessKeyID,
+		sc.RoleARN,
+		sc.SourceProfileName,
+		sc.CredentialSource,
+		sc.CredentialProcess,
+		sc.WebIdentityTokenFile,
+		sc.SSOSessionName,
+		sc.SSOAccountID,
+		sc.SSORoleName,
+	}, "|")))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,131 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/sunil-saini/astat/internal/cache"
+)
+
+func TestIdentityUnmarshalLegacy(t *testing.T) {
+	var identities map[string]identity
+	data := `{"old":"111111111111","new":{"account":"222222222222","source":"abc","verified":"2026-01-02T03:04:05Z"}}`
+	if err := json.Unmarshal([]byte(data), &identities); err != nil {
+		t.Fatal(err)
+	}
+	if old := identities["old"]; old.Account != "111111111111" || !old.Verified.IsZero() {
+		t.Errorf("legacy identity = %+v, want the account with no verification time", old)
+	}
+	if id := identities["new"]; id.Account != "222222222222" || id.Source != "abc" || id.Verified.IsZero() {
+		t.Errorf("identity = %+v", id)
+	}
+}
+
+// setupIdentity points the cache and the AWS config files at a temporary
+// directory holding a profile with static credentials
+func setupIdentity(t *testing.T, accessKey string) {
+	t.Helper()
+	dir := t.TempDir()
+	viper.Set("cache_dir", dir)
+	viper.Set("profile", "work")
+	t.Cleanup(func() {
+		viper.Set("cache_dir", "")
+		viper.Set("profile", "")
+	})
+
+	creds := filepath.Join(dir, "credentials")
+	content := "[work]\naws_access_key_id = " + accessKey + "\naws_secret_access_key = secret\n"
+	if err := os.WriteFile(creds, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", creds)
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+
+	identityMu.Lock()
+	clear(verified)
+	identityMu.Unlock()
+}
+
+func recordIdentity(t *testing.T, id identity) {
+	t.Helper()
+	if err := cache.Write(cache.Path(cache.Dir(), "identities"), map[string]identity{"work": id}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveContextRecordedIdentity(t *testing.T) {
+	ctx := context.Background()
+	setupIdentity(t, "AKIAOLD")
+	source := credentialSource(ctx, "work")
+	if source == "" {
+		t.Fatal("no credential source for the work profile")
+	}
+
+	tests := []struct {
+		name    string
+		id      identity
+		account string
+		wantErr bool
+	}{
+		{
+			name:    "fresh",
+			id:      identity{Account: "111111111111", Source: source, Verified: time.Now()},
+			account: "111111111111",
+		},
+		{
+			// STS cannot be reached, the recorded account is kept
+			name:    "expired",
+			id:      identity{Account: "111111111111", Source: source, Verified: time.Now().Add(-2 * identityTTL)},
+			account: "111111111111",
+		},
+		{
+			name:    "legacy",
+			id:      identity{Account: "111111111111"},
+			account: "111111111111",
+		},
+		{
+			// Other credentials may belong to another account, which must be
+			// looked up
+			name:    "credentials changed",
+			id:      identity{Account: "111111111111", Source: "0123456789abcdef", Verified: time.Now()},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recordIdentity(t, tt.id)
+			cctx, err := ResolveContext(ctx, failingConfig)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("context = %+v, want an error from the account lookup", cctx)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cctx.AccountID != tt.account || cctx.Profile != "work" {
+				t.Errorf("context = %+v, want account %s of profile work", cctx, tt.account)
+			}
+			if id := readIdentities()["work"]; id.Verified.Before(time.Now().Add(-identityTTL)) && id.Checked.IsZero() {
+				t.Error("failed confirmation not recorded, it would be retried on every command")
+			}
+		})
+	}
+}
+
+func TestCredentialSourceChanges(t *testing.T) {
+	ctx := context.Background()
+	setupIdentity(t, "AKIAOLD")
+	before := credentialSource(ctx, "work")
+
+	setupIdentity(t, "AKIANEW")
+	if after := credentialSource(ctx, "work"); after == before {
+		t.Errorf("credential source %s unchanged after switching access keys", after)
+	}
+}
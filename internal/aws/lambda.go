@@ -6,6 +6,7 @@ import (
 
 	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
 	"github.com/sunil-saini/astat/internal/model"
 )
 
@@ -13,6 +14,7 @@ func FetchLambdaFunctions(ctx context.Context, cfg sdkaws.Config) ([]model.Lambd
 	client := lambda.NewFromConfig(cfg)
 
 	var funcs []model.LambdaFunction
+	var arns []string
 	var marker *string
 
 	for {
@@ -27,13 +29,8 @@ func FetchLambdaFunctions(ctx context.Context, cfg sdkaws.Config) ([]model.Lambd
 		}
 
 		for _, f := range out.Functions {
-			funcs = append(funcs, model.LambdaFunction{
-				Name:         sdkaws.ToString(f.FunctionName),
-				Runtime:      string(f.Runtime),
-				LastModified: sdkaws.ToString(f.LastModified),
-				Memory:       fmt.Sprintf("%d", sdkaws.ToInt32(f.MemorySize)),
-				Timeout:      fmt.Sprintf("%d", sdkaws.ToInt32(f.Timeout)),
-			})
+			funcs = append(funcs, mapLambdaFunction(f))
+			arns = append(arns, sdkaws.ToString(f.FunctionArn))
 		}
 
 		if out.NextMarker == nil {
@@ -42,5 +39,50 @@ func FetchLambdaFunctions(ctx context.Context, cfg sdkaws.Config) ([]model.Lambd
 		marker = out.NextMarker
 	}
 
+	err := addTags(ctx, cfg, "lambda:function", len(funcs),
+		func(i int) string { return arns[i] },
+		func(i int, tags map[string]string) { funcs[i].Tags = tags },
+		func(i int) (map[string]string, error) {
+			out, err := client.ListTags(ctx, &lambda.ListTagsInput{Resource: &arns[i]})
+			if err != nil {
+				return nil, err
+			}
+			return out.Tags, nil
+		})
+	if err != nil {
+		return funcs, partialResult([]error{err})
+	}
+
 	return funcs, nil
 }
+
+// DescribeLambdaFunction fetches the function with the given name or ARN
+func DescribeLambdaFunction(ctx context.Context, cfg sdkaws.Config, name string) ([]model.LambdaFunction, error) {
+	client := lambda.NewFromConfig(cfg)
+
+	out, err := client.GetFunction(ctx, &lambda.GetFunctionInput{
+		FunctionName: &name,
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if out.Configuration == nil {
+		return nil, nil
+	}
+	fn := mapLambdaFunction(*out.Configuration)
+	fn.Tags = out.Tags
+	return []model.LambdaFunction{fn}, nil
+}
+
+func mapLambdaFunction(f lambdaTypes.FunctionConfiguration) model.LambdaFunction {
+	return model.LambdaFunction{
+		Name:         sdkaws.ToString(f.FunctionName),
+		Runtime:      string(f.Runtime),
+		LastModified: sdkaws.ToString(f.LastModified),
+		Memory:       fmt.Sprintf("%d", sdkaws.ToInt32(f.MemorySize)),
+		Timeout:      fmt.Sprintf("%d", sdkaws.ToInt32(f.Timeout)),
+	}
+}
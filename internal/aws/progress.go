@@ -0,0 +1,21 @@
+package aws
+
+import "context"
+
+type progressKey struct{}
+
+// ProgressFunc receives the number of resources fetched so far, e.g. 1200
+// "instances"
+type ProgressFunc func(count int, noun string)
+
+// WithProgress returns a context carrying a callback the fetchers report
+// their partial progress to while paging through large result sets
+func WithProgress(ctx context.Context, report ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, report)
+}
+
+func reportProgress(ctx context.Context, count int, noun string) {
+	if report, ok := ctx.Value(progressKey{}).(ProgressFunc); ok {
+		report(count, noun)
+	}
+}
@@ -0,0 +1,72 @@
+package aws
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+	"github.com/spf13/viper"
+)
+
+// rateLimiter spaces out requests evenly so they never exceed a rate
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// wait blocks until the next request is allowed, or ctx is done
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+var (
+	limiterOnce sync.Once
+	limiter     *rateLimiter
+)
+
+// sharedLimiter returns the limiter shared by every AWS client, allowing the
+// rate-limit config of requests per second, or nil when it is not set
+func sharedLimiter() *rateLimiter {
+	limiterOnce.Do(func() {
+		if rps := viper.GetFloat64("rate-limit"); rps > 0 {
+			limiter = &rateLimiter{interval: time.Duration(float64(time.Second) / rps)}
+		}
+	})
+	return limiter
+}
+
+// rateLimitMiddleware makes every attempt of an API call, retries included,
+// wait for the limiter
+func rateLimitMiddleware(l *rateLimiter) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("astatRateLimit",
+			func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+				if err := l.wait(ctx); err != nil {
+					return middleware.FinalizeOutput{}, middleware.Metadata{}, err
+				}
+				return next.HandleFinalize(ctx, in)
+			}), middleware.After)
+	}
+}
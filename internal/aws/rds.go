@@ -33,6 +33,38 @@ func FetchRDSInstances(ctx context.Context, cfg aws.Config) ([]model.RDSInstance
 	return instances, nil
 }
 
+// DescribeRDSInstance fetches the DB instance with the given identifier
+func DescribeRDSInstance(ctx context.Context, cfg aws.Config, id string) ([]model.RDSInstance, error) {
+	client := rds.NewFromConfig(cfg)
+
+	out, err := client.DescribeDBInstances(ctx, &rds.DescribeDBInstancesInput{
+		DBInstanceIdentifier: &id,
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var instances []model.RDSInstance
+	for _, db := range out.DBInstances {
+		roles := make(map[string]string)
+		if db.DBClusterIdentifier != nil {
+			clusters, err := client.DescribeDBClusters(ctx, &rds.DescribeDBClustersInput{
+				DBClusterIdentifier: db.DBClusterIdentifier,
+			})
+			if err == nil {
+				for _, cluster := range clusters.DBClusters {
+					extractClusterMemberRoles(cluster.DBClusterMembers, roles)
+				}
+			}
+		}
+		instances = append(instances, mapRDSInstance(db, roles))
+	}
+	return instances, nil
+}
+
 func fetchRDSClusterRoles(ctx context.Context, client *rds.Client) (map[string]string, error) {
 	roles := make(map[string]string)
 	paginator := rds.NewDescribeDBClustersPaginator(client, &rds.DescribeDBClustersInput{})
@@ -87,6 +119,7 @@ func mapRDSInstance(db rdsTypes.DBInstance, roles map[string]string) model.RDSIn
 		Endpoint:           endpoint,
 		InstanceClass:      *db.DBInstanceClass,
 		AvailabilityZone:   *db.AvailabilityZone,
+		Tags:               tagsFrom(db.TagList, rdsTag),
 	}
 }
 
@@ -108,6 +141,27 @@ func FetchRDSClusters(ctx context.Context, cfg aws.Config) ([]model.RDSCluster,
 	return clusters, nil
 }
 
+// DescribeRDSCluster fetches the DB cluster with the given identifier
+func DescribeRDSCluster(ctx context.Context, cfg aws.Config, id string) ([]model.RDSCluster, error) {
+	client := rds.NewFromConfig(cfg)
+
+	out, err := client.DescribeDBClusters(ctx, &rds.DescribeDBClustersInput{
+		DBClusterIdentifier: &id,
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var clusters []model.RDSCluster
+	for _, db := range out.DBClusters {
+		clusters = append(clusters, mapRDSCluster(db))
+	}
+	return clusters, nil
+}
+
 func mapRDSCluster(db rdsTypes.DBCluster) model.RDSCluster {
 	storageType := ""
 	if db.StorageType != nil {
@@ -146,5 +200,10 @@ func mapRDSCluster(db rdsTypes.DBCluster) model.RDSCluster {
 		ReaderEndpoint:    readerEndpoint,
 		CreateTime:        db.ClusterCreateTime.Format("2006-01-02 15:04:05"),
 		IsPublic:          public,
+		Tags:              tagsFrom(db.TagList, rdsTag),
 	}
 }
+
+func rdsTag(t rdsTypes.Tag) (*string, *string) {
+	return t.Key, t.Value
+}
@@ -0,0 +1,102 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/spf13/viper"
+	"github.com/sunil-saini/astat/internal/cache"
+)
+
+// AllRegions is the regions value that expands to every enabled region
+const AllRegions = "all"
+
+var (
+	regionsMu sync.Mutex
+	// discovered holds the enabled regions found by DescribeRegions per account
+	discovered = make(map[string][]string)
+)
+
+// ConfiguredRegions returns the regions set with --regions or the regions
+// config key, accepting both lists and comma separated values
+func ConfiguredRegions() []string {
+	var regions []string
+	for _, r := range viper.GetStringSlice("regions") {
+		for part := range strings.SplitSeq(r, ",") {
+			if part = strings.TrimSpace(part); part != "" && !slices.Contains(regions, part) {
+				regions = append(regions, part)
+			}
+		}
+	}
+	return regions
+}
+
+// ResolveRegions returns the regions of an account to serve data for. With
+// "all", the enabled regions discovered by the last refresh are reused so
+// reading the cache stays offline
+func ResolveRegions(ctx context.Context, cfg sdkaws.Config, cctx cache.Context) ([]string, error) {
+	regions := ConfiguredRegions()
+	if !slices.Contains(regions, AllRegions) {
+		return regionsOrDefault(regions, cfg), nil
+	}
+
+	if known, ok := readRegions()[cctx.AccountID]; ok && len(known) > 0 {
+		return known, nil
+	}
+	return LookupRegions(ctx, cfg, cctx)
+}
+
+// LookupRegions is like ResolveRegions but discovers the enabled regions
+// with EC2 DescribeRegions (once per process and account)
+func LookupRegions(ctx context.Context, cfg sdkaws.Config, cctx cache.Context) ([]string, error) {
+	regions := ConfiguredRegions()
+	if !slices.Contains(regions, AllRegions) {
+		return regionsOrDefault(regions, cfg), nil
+	}
+
+	regionsMu.Lock()
+	defer regionsMu.Unlock()
+
+	if known, ok := discovered[cctx.AccountID]; ok {
+		return known, nil
+	}
+
+	out, err := ec2.NewFromConfig(cfg).DescribeRegions(ctx, &ec2.DescribeRegionsInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover regions: %w", err)
+	}
+
+	var enabled []string
+	for _, r := range out.Regions {
+		enabled = append(enabled, sdkaws.ToString(r.RegionName))
+	}
+	sort.Strings(enabled)
+	discovered[cctx.AccountID] = enabled
+
+	known := readRegions()
+	known[cctx.AccountID] = enabled
+	if err := cache.EnsureDir(cache.Dir()); err == nil {
+		_ = cache.Write(cache.Path(cache.Dir(), "regions"), known)
+	}
+
+	return enabled, nil
+}
+
+func regionsOrDefault(regions []string, cfg sdkaws.Config) []string {
+	if len(regions) == 0 {
+		return []string{cfg.Region}
+	}
+	return regions
+}
+
+func readRegions() map[string][]string {
+	known := make(map[string][]string)
+	_ = cache.Read(cache.Path(cache.Dir(), "regions"), &known)
+	return known
+}
@@ -3,6 +3,7 @@ package aws
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 
@@ -31,17 +32,7 @@ func FetchHostedZones(ctx context.Context, cfg sdkaws.Config) ([]model.Route53Ho
 		}
 
 		for _, z := range out.HostedZones {
-			zoneType := "public"
-			if z.Config.PrivateZone {
-				zoneType = "private"
-			}
-
-			zones = append(zones, model.Route53HostedZone{
-				ID:      *z.Id,
-				Name:    *z.Name,
-				Type:    zoneType,
-				Records: fmt.Sprintf("%d", *z.ResourceRecordSetCount),
-			})
+			zones = append(zones, mapHostedZone(z))
 		}
 
 		if !out.IsTruncated {
@@ -50,17 +41,109 @@ func FetchHostedZones(ctx context.Context, cfg sdkaws.Config) ([]model.Route53Ho
 		marker = out.NextMarker
 	}
 
+	if err := addHostedZoneTags(ctx, client, zones); err != nil {
+		return zones, partialResult([]error{err})
+	}
+	return zones, nil
+}
+
+// DescribeHostedZone fetches the hosted zone with the given ID, or the zones
+// with the given domain name
+func DescribeHostedZone(ctx context.Context, cfg sdkaws.Config, id string) ([]model.Route53HostedZone, error) {
+	client := route53.NewFromConfig(cfg)
+
+	if !strings.Contains(id, ".") {
+		out, err := client.GetHostedZone(ctx, &route53.GetHostedZoneInput{
+			Id: sdkaws.String(strings.TrimPrefix(id, "/hostedzone/")),
+		})
+		if err != nil {
+			if isNotFound(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		zones := []model.Route53HostedZone{mapHostedZone(*out.HostedZone)}
+		if err := addHostedZoneTags(ctx, client, zones); err != nil {
+			return zones, partialResult([]error{err})
+		}
+		return zones, nil
+	}
+
+	name := strings.TrimSuffix(id, ".") + "."
+	out, err := client.ListHostedZonesByName(ctx, &route53.ListHostedZonesByNameInput{
+		DNSName: &name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var zones []model.Route53HostedZone
+	for _, z := range out.HostedZones {
+		if strings.EqualFold(sdkaws.ToString(z.Name), name) {
+			zones = append(zones, mapHostedZone(z))
+		}
+	}
+	if err := addHostedZoneTags(ctx, client, zones); err != nil {
+		return zones, partialResult([]error{err})
+	}
 	return zones, nil
 }
 
+// addHostedZoneTags fetches the tags of the zones, 10 zones per request. The
+// tags of the other batches are still set when some fail
+func addHostedZoneTags(ctx context.Context, client *route53.Client, zones []model.Route53HostedZone) error {
+	byID := make(map[string]*model.Route53HostedZone, len(zones))
+	ids := make([]string, 0, len(zones))
+	for i := range zones {
+		id := strings.TrimPrefix(zones[i].ID, "/hostedzone/")
+		byID[id] = &zones[i]
+		ids = append(ids, id)
+	}
+
+	failed := &tagErrors{}
+	for batch := range slices.Chunk(ids, 10) {
+		out, err := client.ListTagsForResources(ctx, &route53.ListTagsForResourcesInput{
+			ResourceIds:  batch,
+			ResourceType: types.TagResourceTypeHostedzone,
+		})
+		if err != nil {
+			for range batch {
+				failed.add(err)
+			}
+			continue
+		}
+		for _, set := range out.ResourceTagSets {
+			if zone, ok := byID[sdkaws.ToString(set.ResourceId)]; ok {
+				zone.Tags = tagsFrom(set.Tags, func(t types.Tag) (*string, *string) { return t.Key, t.Value })
+			}
+		}
+	}
+	return failed.err("hosted zones", len(ids))
+}
+
+func mapHostedZone(z types.HostedZone) model.Route53HostedZone {
+	zoneType := "public"
+	if z.Config != nil && z.Config.PrivateZone {
+		zoneType = "private"
+	}
+
+	return model.Route53HostedZone{
+		ID:      *z.Id,
+		Name:    *z.Name,
+		Type:    zoneType,
+		Records: fmt.Sprintf("%d", sdkaws.ToInt64(z.ResourceRecordSetCount)),
+	}
+}
+
 func FetchAllRoute53Records(ctx context.Context, cfg sdkaws.Config) ([]model.Route53Record, error) {
 	client := route53.NewFromConfig(cfg)
 	maxRecords := viper.GetInt("route53-max-records")
 
 	var allRecords []model.Route53Record
+	var errs []error
 	var mu sync.Mutex
 	var wg sync.WaitGroup
-	sem := make(chan struct{}, 5) // Limit concurrency to 5
+	sem := make(chan struct{}, max(viper.GetInt("route53-zone-concurrency"), 1))
 
 	var marker *string
 	for {
@@ -89,9 +172,12 @@ func FetchAllRoute53Records(ctx context.Context, cfg sdkaws.Config) ([]model.Rou
 				sem <- struct{}{}        // Acquire
 				defer func() { <-sem }() // Release
 
-				records := fetchZoneRecords(ctx, client, zID, zName)
+				records, err := fetchZoneRecords(ctx, client, zID, zName)
 				mu.Lock()
 				allRecords = append(allRecords, records...)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("records of zone %s: %w", zName, err))
+				}
 				mu.Unlock()
 			}(zoneID, zoneName)
 		}
@@ -103,17 +189,22 @@ func FetchAllRoute53Records(ctx context.Context, cfg sdkaws.Config) ([]model.Rou
 	}
 
 	wg.Wait()
-	return allRecords, nil
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return allRecords, partialResult(errs)
 }
 
-func fetchZoneRecords(ctx context.Context, client *route53.Client, zoneID, zoneName string) []model.Route53Record {
+// fetchZoneRecords fetches the records of a zone, returning those collected
+// before a failing page along with its error
+func fetchZoneRecords(ctx context.Context, client *route53.Client, zoneID, zoneName string) ([]model.Route53Record, error) {
 	var records []model.Route53Record
 	var startName *string
 	var startType types.RRType
 
 	for {
 		if err := ctx.Err(); err != nil {
-			return records
+			return records, err
 		}
 		rout, err := client.ListResourceRecordSets(ctx, &route53.ListResourceRecordSetsInput{
 			HostedZoneId:    &zoneID,
@@ -122,7 +213,7 @@ func fetchZoneRecords(ctx context.Context, client *route53.Client, zoneID, zoneN
 			MaxItems:        sdkaws.Int32(300),
 		})
 		if err != nil {
-			return records
+			return records, err
 		}
 
 		for _, r := range rout.ResourceRecordSets {
@@ -135,7 +226,7 @@ func fetchZoneRecords(ctx context.Context, client *route53.Client, zoneID, zoneN
 		startName = rout.NextRecordName
 		startType = rout.NextRecordType
 	}
-	return records
+	return records, nil
 }
 
 func mapRoute53RecordSet(zoneName string, r types.ResourceRecordSet) model.Route53Record {
@@ -162,5 +253,7 @@ func mapRoute53RecordSet(zoneName string, r types.ResourceRecordSet) model.Route
 		Type:     recordType,
 		TTL:      ttl,
 		Value:    value,
+
+		SetIdentifier: sdkaws.ToString(r.SetIdentifier),
 	}
 }
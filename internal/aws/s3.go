@@ -2,18 +2,43 @@ package aws
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/sunil-saini/astat/internal/model"
 )
 
 func FetchS3Buckets(ctx context.Context, cfg sdkaws.Config) ([]model.S3Bucket, error) {
+	return listS3Buckets(ctx, cfg, nil)
+}
+
+// DescribeS3Bucket fetches the bucket with the given name
+func DescribeS3Bucket(ctx context.Context, cfg sdkaws.Config, name string) ([]model.S3Bucket, error) {
+	buckets, err := listS3Buckets(ctx, cfg, &name)
+	var partial *PartialError
+	if err != nil && !errors.As(err, &partial) {
+		return nil, err
+	}
+
+	var found []model.S3Bucket
+	for _, b := range buckets {
+		if b.Name == name {
+			found = append(found, b)
+		}
+	}
+	return found, err
+}
+
+func listS3Buckets(ctx context.Context, cfg sdkaws.Config, prefix *string) ([]model.S3Bucket, error) {
 	client := s3.NewFromConfig(cfg)
 
 	paginator := s3.NewListBucketsPaginator(client, &s3.ListBucketsInput{
 		MaxBuckets: sdkaws.Int32(1000),
+		Prefix:     prefix,
 	})
 
 	var buckets []model.S3Bucket
@@ -32,5 +57,60 @@ func FetchS3Buckets(ctx context.Context, cfg sdkaws.Config) ([]model.S3Bucket, e
 		}
 	}
 
-	return buckets, nil
+	// Describing a bucket tags it directly rather than listing every tagged
+	// bucket in its region
+	return buckets, partialResult(addS3Tags(ctx, cfg, buckets, prefix == nil))
+}
+
+// addS3Tags fetches the tags of each bucket from the bucket's own region,
+// per region from the tagging API when batch is set
+func addS3Tags(ctx context.Context, cfg sdkaws.Config, buckets []model.S3Bucket, batch bool) []error {
+	byRegion := make(map[string][]int)
+	for i, b := range buckets {
+		byRegion[b.Region] = append(byRegion[b.Region], i)
+	}
+
+	var errs []error
+	bucketErrs := &tagErrors{}
+	for region, indexes := range byRegion {
+		regionCfg := cfg.Copy()
+		if region != "" {
+			regionCfg.Region = region
+		}
+		client := s3.NewFromConfig(regionCfg)
+
+		fetch := func(i int) (map[string]string, error) {
+			b := &buckets[indexes[i]]
+			out, err := client.GetBucketTagging(ctx, &s3.GetBucketTaggingInput{Bucket: &b.Name})
+			if err != nil {
+				// A bucket without tags has no tag set
+				if isNotFound(err) {
+					return nil, nil
+				}
+				return nil, err
+			}
+			return tagsFrom(out.TagSet, func(t s3Types.Tag) (*string, *string) { return t.Key, t.Value }), nil
+		}
+		set := func(i int, tags map[string]string) { buckets[indexes[i]].Tags = tags }
+
+		if batch {
+			arn := func(i int) string { return "arn:" + partition(regionCfg.Region) + ":s3:::" + buckets[indexes[i]].Name }
+			if err := addTags(ctx, regionCfg, "s3", len(indexes), arn, set, fetch); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", regionCfg.Region, err))
+			}
+			continue
+		}
+		fetchEach(ctx, len(indexes), func(i int) {
+			tags, err := fetch(i)
+			if err != nil {
+				bucketErrs.add(err)
+				return
+			}
+			set(i, tags)
+		})
+	}
+	if err := bucketErrs.err("s3", len(buckets)); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
 }
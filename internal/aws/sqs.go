@@ -2,6 +2,7 @@ package aws
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
@@ -15,6 +16,7 @@ func FetchSQSQueues(ctx context.Context, cfg sdkaws.Config) ([]model.SQSQueue, e
 	paginator := sqs.NewListQueuesPaginator(client, &sqs.ListQueuesInput{})
 
 	var queues []model.SQSQueue
+	var urls []string
 	for paginator.HasMorePages() {
 		page, err := paginator.NextPage(ctx)
 		if err != nil {
@@ -22,18 +24,71 @@ func FetchSQSQueues(ctx context.Context, cfg sdkaws.Config) ([]model.SQSQueue, e
 		}
 
 		for _, url := range page.QueueUrls {
-			name := url[strings.LastIndex(url, "/")+1:]
-			qType := "Standard"
-			if strings.HasSuffix(name, ".fifo") {
-				qType = "FIFO"
-			}
-
-			queues = append(queues, model.SQSQueue{
-				Name: name,
-				Type: qType,
-			})
+			queues = append(queues, mapSQSQueue(url))
+			urls = append(urls, url)
 		}
 	}
 
+	err := addTags(ctx, cfg, "sqs", len(queues),
+		func(i int) string { return sqsQueueARN(cfg.Region, urls[i]) },
+		func(i int, tags map[string]string) { queues[i].Tags = tags },
+		func(i int) (map[string]string, error) { return fetchSQSQueueTags(ctx, client, urls[i]) })
+	if err != nil {
+		return queues, partialResult([]error{err})
+	}
+
 	return queues, nil
 }
+
+// DescribeSQSQueue fetches the queue with the given name
+func DescribeSQSQueue(ctx context.Context, cfg sdkaws.Config, name string) ([]model.SQSQueue, error) {
+	client := sqs.NewFromConfig(cfg)
+
+	out, err := client.GetQueueUrl(ctx, &sqs.GetQueueUrlInput{
+		QueueName: &name,
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	queue := mapSQSQueue(sdkaws.ToString(out.QueueUrl))
+	queue.Tags, err = fetchSQSQueueTags(ctx, client, sdkaws.ToString(out.QueueUrl))
+	if err != nil {
+		return []model.SQSQueue{queue}, partialResult([]error{fmt.Errorf("tags: %w", err)})
+	}
+	return []model.SQSQueue{queue}, nil
+}
+
+func fetchSQSQueueTags(ctx context.Context, client *sqs.Client, url string) (map[string]string, error) {
+	out, err := client.ListQueueTags(ctx, &sqs.ListQueueTagsInput{QueueUrl: &url})
+	if err != nil {
+		return nil, err
+	}
+	return out.Tags, nil
+}
+
+// sqsQueueARN builds the ARN of a queue from its URL, which ends in the
+// account ID and queue name
+func sqsQueueARN(region, url string) string {
+	parts := strings.Split(url, "/")
+	if len(parts) < 2 {
+		return ""
+	}
+	account, name := parts[len(parts)-2], parts[len(parts)-1]
+	return "arn:" + partition(region) + ":sqs:" + region + ":" + account + ":" + name
+}
+
+func mapSQSQueue(url string) model.SQSQueue {
+	name := url[strings.LastIndex(url, "/")+1:]
+	qType := "Standard"
+	if strings.HasSuffix(name, ".fifo") {
+		qType = "FIFO"
+	}
+
+	return model.SQSQueue{
+		Name: name,
+		Type: qType,
+	}
+}
@@ -2,18 +2,34 @@ package aws
 
 import (
 	"context"
-
+	"fmt"
 	"strings"
 
 	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmTypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
 	"github.com/sunil-saini/astat/internal/model"
 )
 
 func FetchSSMParameters(ctx context.Context, cfg sdkaws.Config) ([]model.SSMParameter, error) {
+	return describeSSMParameters(ctx, cfg, nil)
+}
+
+// DescribeSSMParameter fetches the metadata of the parameter with the given
+// name, without its value
+func DescribeSSMParameter(ctx context.Context, cfg sdkaws.Config, name string) ([]model.SSMParameter, error) {
+	return describeSSMParameters(ctx, cfg, []ssmTypes.ParameterStringFilter{{
+		Key:    sdkaws.String("Name"),
+		Option: sdkaws.String("Equals"),
+		Values: []string{name},
+	}})
+}
+
+func describeSSMParameters(ctx context.Context, cfg sdkaws.Config, filters []ssmTypes.ParameterStringFilter) ([]model.SSMParameter, error) {
 	client := ssm.NewFromConfig(cfg)
 
 	var params []model.SSMParameter
+	var arns []string
 	var nextToken *string
 
 	for {
@@ -21,8 +37,9 @@ func FetchSSMParameters(ctx context.Context, cfg sdkaws.Config) ([]model.SSMPara
 			return nil, err
 		}
 		out, err := client.DescribeParameters(ctx, &ssm.DescribeParametersInput{
-			NextToken:  nextToken,
-			MaxResults: sdkaws.Int32(50),
+			NextToken:        nextToken,
+			MaxResults:       sdkaws.Int32(50),
+			ParameterFilters: filters,
 		})
 		if err != nil {
 			return nil, err
@@ -35,6 +52,7 @@ func FetchSSMParameters(ctx context.Context, cfg sdkaws.Config) ([]model.SSMPara
 				LastModified: p.LastModifiedDate.Format("2006-01-02 15:04:05"),
 				ModifiedBy:   shortenARN(*p.LastModifiedUser),
 			})
+			arns = append(arns, sdkaws.ToString(p.ARN))
 		}
 
 		if out.NextToken == nil {
@@ -43,6 +61,38 @@ func FetchSSMParameters(ctx context.Context, cfg sdkaws.Config) ([]model.SSMPara
 		nextToken = out.NextToken
 	}
 
+	// A single parameter is cheaper to tag directly than by listing every
+	// tagged parameter in the region
+	fetchTags := func(i int) (map[string]string, error) {
+		out, err := client.ListTagsForResource(ctx, &ssm.ListTagsForResourceInput{
+			ResourceId:   &params[i].Name,
+			ResourceType: ssmTypes.ResourceTypeForTaggingParameter,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return tagsFrom(out.TagList, func(t ssmTypes.Tag) (*string, *string) { return t.Key, t.Value }), nil
+	}
+	if filters != nil {
+		if len(params) == 0 {
+			return nil, nil
+		}
+		tags, err := fetchTags(0)
+		if err != nil {
+			return params, partialResult([]error{fmt.Errorf("tags: %w", err)})
+		}
+		params[0].Tags = tags
+		return params, nil
+	}
+
+	err := addTags(ctx, cfg, "ssm:parameter", len(params),
+		func(i int) string { return arns[i] },
+		func(i int, tags map[string]string) { params[i].Tags = tags },
+		fetchTags)
+	if err != nil {
+		return params, partialResult([]error{err})
+	}
+
 	return params, nil
 }
 
@@ -0,0 +1,110 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
+	tagging "github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	taggingTypes "github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+)
+
+// tagsFrom converts an SDK tag list into a map
+func tagsFrom[T any](tags []T, kv func(T) (*string, *string)) map[string]string {
+	m := make(map[string]string, len(tags))
+	for _, t := range tags {
+		if k, v := kv(t); k != nil {
+			m[*k] = sdkaws.ToString(v)
+		}
+	}
+	return m
+}
+
+// addTags sets the tags of n resources of a type, e.g. "lambda:function",
+// from a few paginated requests to the Resource Groups Tagging API. When that
+// API is not allowed it falls back to fetch, one request per resource, and
+// returns an error when some of those fail
+func addTags(ctx context.Context, cfg sdkaws.Config, resourceType string, n int, arn func(i int) string, set func(i int, tags map[string]string), fetch func(i int) (map[string]string, error)) error {
+	if n == 0 {
+		return nil
+	}
+
+	if tags, err := fetchResourceTags(ctx, cfg, resourceType); err == nil {
+		for i := range n {
+			// Resources without tags are not listed
+			set(i, tags[arn(i)])
+		}
+		return nil
+	}
+
+	errs := &tagErrors{}
+	fetchEach(ctx, n, func(i int) {
+		tags, err := fetch(i)
+		if err != nil {
+			errs.add(err)
+			return
+		}
+		set(i, tags)
+	})
+	return errs.err(resourceType, n)
+}
+
+// fetchResourceTags fetches the tags of every tagged resource of a type in
+// the region, keyed by ARN
+func fetchResourceTags(ctx context.Context, cfg sdkaws.Config, resourceType string) (map[string]map[string]string, error) {
+	paginator := tagging.NewGetResourcesPaginator(tagging.NewFromConfig(cfg), &tagging.GetResourcesInput{
+		ResourceTypeFilters: []string{resourceType},
+		ResourcesPerPage:    sdkaws.Int32(100),
+	})
+
+	tags := make(map[string]map[string]string)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range page.ResourceTagMappingList {
+			tags[sdkaws.ToString(m.ResourceARN)] = tagsFrom(m.Tags, func(t taggingTypes.Tag) (*string, *string) { return t.Key, t.Value })
+		}
+	}
+	return tags, nil
+}
+
+// tagErrors collects the errors of per-resource tag requests, reported as a
+// single error so a missing permission gives one warning rather than one per
+// resource
+type tagErrors struct {
+	mu     sync.Mutex
+	failed int
+	first  error
+}
+
+func (e *tagErrors) add(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.failed++
+	if e.first == nil {
+		e.first = err
+	}
+}
+
+// err returns the error of the requests that failed out of total, or nil
+func (e *tagErrors) err(kind string, total int) error {
+	if e.failed == 0 {
+		return nil
+	}
+	return fmt.Errorf("tags of %d of %d %s: %w", e.failed, total, kind, e.first)
+}
+
+// partition returns the ARN partition of a region
+func partition(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return "aws-cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		return "aws-us-gov"
+	}
+	return "aws"
+}
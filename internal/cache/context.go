@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// GlobalRegion is the region of the context global services (S3, CloudFront,
+// Route53) are cached under, so they are shared by every region
+const GlobalRegion = "global"
+
+// Context identifies the AWS account, profile and region a set of cached
+// services belongs to
+type Context struct {
+	AccountID string `json:"account_id"`
+	Profile   string `json:"profile"`
+	Region    string `json:"region"`
+}
+
+func (c Context) String() string {
+	return fmt.Sprintf("%s (%s/%s)", c.AccountID, c.Profile, c.Region)
+}
+
+// Global returns the context global services of the same account and profile
+// are cached under
+func (c Context) Global() Context {
+	c.Region = GlobalRegion
+	return c
+}
+
+// ContextDir returns the directory holding the cache files of a context:
+// <cache_dir>/<account>/<profile>/<region>
+func ContextDir(c Context) string {
+	return filepath.Join(Dir(), pathSafe(c.AccountID), pathSafe(c.Profile), pathSafe(c.Region))
+}
+
+// MetaPath returns the path of the metadata file of a context
+func MetaPath(c Context) string {
+	return Path(ContextDir(c), "meta")
+}
+
+// Contexts lists every context that has cache metadata on disk
+func Contexts() ([]Context, error) {
+	matches, err := filepath.Glob(filepath.Join(Dir(), "*", "*", "*", "meta.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var contexts []Context
+	for _, m := range matches {
+		var meta Meta
+		if err := Read(m, &meta); err != nil || meta.Context.AccountID == "" {
+			continue
+		}
+		contexts = append(contexts, meta.Context)
+	}
+
+	sort.Slice(contexts, func(i, j int) bool {
+		return contexts[i].String() < contexts[j].String()
+	})
+	return contexts, nil
+}
+
+func pathSafe(s string) string {
+	if s == "" {
+		return "_"
+	}
+	return strings.NewReplacer("/", "_", string(os.PathSeparator), "_", "..", "_").Replace(s)
+}
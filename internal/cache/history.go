@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SnapshotsKey is the number of snapshots kept per service, 0 disabling them
+const SnapshotsKey = "history-snapshots"
+
+// snapshotLayout names snapshots after the UTC time they were taken at
+const snapshotLayout = "20060102T150405Z"
+
+// Snapshot is a past copy of the cached data of a service
+type Snapshot struct {
+	Name string
+	Time time.Time
+	Path string
+}
+
+// HistoryDir returns the directory holding the snapshots of a service:
+// <context dir>/history/<service>
+func HistoryDir(cacheDir, name string) string {
+	return filepath.Join(cacheDir, "history", name)
+}
+
+// TakeSnapshot copies the cached data of a service into its history, then
+// removes the oldest snapshots beyond keep
+func TakeSnapshot(cacheDir, name string, keep int) error {
+	data, err := os.ReadFile(Path(cacheDir, name))
+	if err != nil {
+		return err
+	}
+
+	dir := HistoryDir(cacheDir, name)
+	if err := EnsureDir(dir); err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, time.Now().UTC().Format(snapshotLayout)+".json")
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+
+	snapshots, err := Snapshots(cacheDir, name)
+	if err != nil {
+		return err
+	}
+	for _, s := range snapshots[:max(len(snapshots)-keep, 0)] {
+		if err := os.Remove(s.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Snapshots lists the snapshots of a service, oldest first
+func Snapshots(cacheDir, name string) ([]Snapshot, error) {
+	dir := HistoryDir(cacheDir, name)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var snapshots []Snapshot
+	for _, e := range entries {
+		snapshot, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || e.IsDir() {
+			continue
+		}
+		t, err := time.Parse(snapshotLayout, snapshot)
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, Snapshot{Name: snapshot, Time: t, Path: filepath.Join(dir, e.Name())})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Time.Before(snapshots[j].Time)
+	})
+	return snapshots, nil
+}
+
+// ParseSnapshotTime parses a snapshot name, or an RFC 3339 or local date and
+// time, to the time it refers to
+func ParseSnapshotTime(s string) (time.Time, error) {
+	if t, err := time.Parse(snapshotLayout, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("expected a snapshot name (e.g. 20240131T150405Z), an RFC 3339 time or a date such as '2024-01-31 15:04'")
+}
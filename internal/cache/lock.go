@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	lockTimeout      = 10 * time.Second
+	lockPollInterval = 50 * time.Millisecond
+)
+
+var (
+	metaMu   sync.Mutex
+	lockFile *os.File
+)
+
+// LockMeta serializes metadata updates across goroutines and astat processes
+// with an advisory flock on meta.lock in the cache directory. It gives up
+// after a timeout. The kernel releases a flock when its holder exits, so a
+// lock still held is never stale, whatever PID the lock file records
+func LockMeta() error {
+	metaMu.Lock()
+
+	f, err := acquireLock(filepath.Join(Dir(), "meta.lock"), lockTimeout)
+	if err != nil {
+		metaMu.Unlock()
+		return err
+	}
+	lockFile = f
+	return nil
+}
+
+func UnlockMeta() {
+	if lockFile != nil {
+		_ = syscall.Flock(int(lockFile.Fd()), syscall.LOCK_UN)
+		lockFile.Close()
+		lockFile = nil
+	}
+	metaMu.Unlock()
+}
+
+func acquireLock(path string, timeout time.Duration) (*os.File, error) {
+	if err := EnsureDir(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			return nil, err
+		}
+
+		err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil && !samePath(f, path) {
+			// The file was removed or replaced between opening and locking
+			// it, the lock is then on an inode nobody else will open
+			f.Close()
+			continue
+		}
+		if err == nil {
+			_ = f.Truncate(0)
+			_, _ = f.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0)
+			return f, nil
+		}
+
+		holder := lockHolder(f)
+		f.Close()
+		if !errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+
+		if time.Now().After(deadline) {
+			if holder > 0 && IsProcessAlive(holder) {
+				return nil, fmt.Errorf("timed out waiting for cache lock held by PID %d", holder)
+			}
+			return nil, fmt.Errorf("timed out waiting for cache lock %s", path)
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// samePath reports whether the open file f is still the file at path
+func samePath(f *os.File, path string) bool {
+	open, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	current, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(open, current)
+}
+
+func lockHolder(f *os.File) int {
+	buf := make([]byte, 16)
+	n, _ := f.ReadAt(buf, 0)
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(buf[:n])))
+	return pid
+}
+
+// IsProcessAlive reports whether a process with the given PID exists
+func IsProcessAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+
+	err = process.Signal(syscall.Signal(0))
+	return err == nil
+}
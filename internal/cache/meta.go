@@ -1,27 +1,70 @@
 package cache
 
 import (
-	"sync"
 	"time"
 )
 
-var metaMu sync.Mutex
-
 type ServiceMeta struct {
 	LastUpdated time.Time `json:"last_updated"`
 	Refreshing  bool      `json:"refreshing"`
 	BusyPID     int       `json:"busy_pid"`
+	// Warnings explain why the last refresh cached partial data, and
+	// ErrorCount is the number of requests that failed during it
+	Warnings   []string `json:"warnings,omitempty"`
+	ErrorCount int      `json:"error_count,omitempty"`
 }
 
 type Meta struct {
+	Context     Context                `json:"context"`
 	LastUpdated time.Time              `json:"last_updated"`
 	Services    map[string]ServiceMeta `json:"services"`
 }
 
-func LockMeta() {
-	metaMu.Lock()
+// IsBusy reports whether a live process is refreshing the service
+func (s ServiceMeta) IsBusy() bool {
+	return s.Refreshing && IsProcessAlive(s.BusyPID)
+}
+
+// IsPartial reports whether the last refresh cached incomplete data
+func (s ServiceMeta) IsPartial() bool {
+	return s.ErrorCount > 0
 }
 
-func UnlockMeta() {
-	metaMu.Unlock()
+// ReadMeta reads the metadata of a context under the metadata lock
+func ReadMeta(c Context) (Meta, error) {
+	var meta Meta
+	if err := LockMeta(); err != nil {
+		return meta, err
+	}
+	defer UnlockMeta()
+
+	err := Read(MetaPath(c), &meta)
+	return meta, err
+}
+
+// UpdateMeta applies fn to the metadata of a context and saves it, holding
+// the metadata lock for the whole read-modify-write. Nothing is written if
+// fn returns an error
+func UpdateMeta(c Context, fn func(*Meta) error) error {
+	if err := LockMeta(); err != nil {
+		return err
+	}
+	defer UnlockMeta()
+
+	var meta Meta
+	path := MetaPath(c)
+	_ = Read(path, &meta)
+	if meta.Services == nil {
+		meta.Services = make(map[string]ServiceMeta)
+	}
+	meta.Context = c
+
+	if err := fn(&meta); err != nil {
+		return err
+	}
+
+	if err := EnsureDir(ContextDir(c)); err != nil {
+		return err
+	}
+	return Write(path, &meta)
 }
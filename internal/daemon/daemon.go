@@ -0,0 +1,136 @@
+// Package daemon holds the protocol spoken over the Unix socket of the
+// background refresh daemon, and the client side of it
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/sunil-saini/astat/internal/aws"
+	"github.com/sunil-saini/astat/internal/cache"
+)
+
+const (
+	CommandRefresh = "refresh"
+	CommandStatus  = "status"
+	CommandStop    = "stop"
+
+	dialTimeout = 500 * time.Millisecond
+	ioTimeout   = 5 * time.Second
+)
+
+// Scope is the AWS account, profile and regions a daemon refreshes, requests
+// for another scope being refused so the caller refreshes by itself
+type Scope struct {
+	AccountID string   `json:"account_id,omitempty"`
+	Profile   string   `json:"profile,omitempty"`
+	Region    string   `json:"region,omitempty"`
+	Regions   []string `json:"regions,omitempty"`
+}
+
+// CurrentScope returns the scope of the running command, as resolved from
+// the AWS config and environment rather than the flags alone
+func CurrentScope(ctx context.Context) (Scope, error) {
+	cfg, err := aws.LoadConfig(ctx)
+	if err != nil {
+		return Scope{}, err
+	}
+	cctx, err := aws.ResolveContext(ctx, cfg)
+	if err != nil {
+		return Scope{}, err
+	}
+	return Scope{
+		AccountID: cctx.AccountID,
+		Profile:   cctx.Profile,
+		Region:    cctx.Region,
+		Regions:   aws.ConfiguredRegions(),
+	}, nil
+}
+
+// Equal reports whether two scopes refresh the same contexts
+func (s Scope) Equal(o Scope) bool {
+	return s.AccountID == o.AccountID && s.Profile == o.Profile && s.Region == o.Region && slices.Equal(s.Regions, o.Regions)
+}
+
+type Request struct {
+	Command string `json:"command"`
+	Service string `json:"service,omitempty"`
+	Scope   Scope  `json:"scope"`
+}
+
+type Response struct {
+	OK     bool    `json:"ok"`
+	Error  string  `json:"error,omitempty"`
+	Status *Status `json:"status,omitempty"`
+}
+
+// Status describes a running daemon
+type Status struct {
+	PID         int                  `json:"pid"`
+	Started     time.Time            `json:"started"`
+	Scope       Scope                `json:"scope"`
+	Interval    time.Duration        `json:"interval"`
+	Refreshing  []string             `json:"refreshing"`
+	LastRefresh map[string]time.Time `json:"last_refresh"`
+}
+
+// SocketPath is the Unix socket the daemon listens on
+func SocketPath() string {
+	return filepath.Join(cache.Dir(), "daemon.sock")
+}
+
+// LogPath is the file the daemon started in the background logs to
+func LogPath() string {
+	return filepath.Join(cache.Dir(), "daemon.log")
+}
+
+// Send sends a request to the daemon and returns its response. It fails fast
+// when no daemon is listening
+func Send(req Request) (Response, error) {
+	conn, err := net.DialTimeout("unix", SocketPath(), dialTimeout)
+	if err != nil {
+		return Response{}, err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(ioTimeout))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return Response{}, err
+	}
+
+	var resp Response
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&resp); err != nil {
+		return Response{}, err
+	}
+	if !resp.OK {
+		return resp, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+// RequestRefresh asks a running daemon to refresh a service without waiting
+// for it, reporting whether the daemon took the request
+func RequestRefresh(ctx context.Context, service string) bool {
+	scope, err := CurrentScope(ctx)
+	if err != nil {
+		return false
+	}
+	_, err = Send(Request{Command: CommandRefresh, Service: service, Scope: scope})
+	return err == nil
+}
+
+// Running returns the status of the running daemon
+func Running() (*Status, error) {
+	resp, err := Send(Request{Command: CommandStatus})
+	if err != nil {
+		return nil, fmt.Errorf("daemon is not running: %w", err)
+	}
+	return resp.Status, nil
+}
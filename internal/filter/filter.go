@@ -0,0 +1,210 @@
+// Package filter implements the field filter expressions accepted by --filter,
+// e.g. State=running,Type~^t3\.,AZ!=us-east-1a
+//
+// A condition is <field><operator><value> with the operators
+//
+//	=   equal (case insensitive, numeric when both sides are numbers)
+//	!=  not equal
+//	~   matches regular expression (case insensitive)
+//	!~  does not match regular expression
+//	>  >=  <  <=  numeric comparison, or lexical when a side is not a number
+//
+// Conditions are combined with "," "&&" or "and" (AND) and "||" or "or" (OR),
+// AND binding tighter than OR, and can be grouped with parentheses. Values
+// containing separators can be quoted with ' or ", separators within
+// parentheses or braces belong to the value, e.g. Name~^web-[0-9]{2,3}$
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Getter returns the value of a field of the item being matched, and whether
+// the item has such a field
+type Getter func(field string) (string, bool)
+
+// Filter is a parsed filter expression
+type Filter struct {
+	root   node
+	fields []string
+}
+
+// Parse compiles a filter expression. An empty expression matches everything
+func Parse(expr string) (*Filter, error) {
+	f := &Filter{}
+	if strings.TrimSpace(expr) == "" {
+		return f, nil
+	}
+
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, filter: f}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %s in filter", p.tokens[p.pos])
+	}
+	f.root = root
+	return f, nil
+}
+
+// Match reports whether the item exposed by get satisfies the filter
+func (f *Filter) Match(get Getter) bool {
+	if f == nil || f.root == nil {
+		return true
+	}
+	return f.root.match(get)
+}
+
+// Fields returns the field names referenced by the filter
+func (f *Filter) Fields() []string {
+	if f == nil {
+		return nil
+	}
+	return f.fields
+}
+
+// Empty reports whether the filter matches everything
+func (f *Filter) Empty() bool {
+	return f == nil || f.root == nil
+}
+
+type node interface {
+	match(get Getter) bool
+}
+
+type andNode []node
+
+func (n andNode) match(get Getter) bool {
+	for _, c := range n {
+		if !c.match(get) {
+			return false
+		}
+	}
+	return true
+}
+
+type orNode []node
+
+func (n orNode) match(get Getter) bool {
+	for _, c := range n {
+		if c.match(get) {
+			return true
+		}
+	}
+	return false
+}
+
+type condition struct {
+	field string
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+// operators is ordered so that two character operators are tried first
+var operators = []string{"!=", "!~", ">=", "<=", "=", "~", ">", "<"}
+
+func parseCondition(text string) (*condition, error) {
+	idx, op := -1, ""
+	for i := 0; i < len(text) && idx < 0; i++ {
+		for _, o := range operators {
+			if strings.HasPrefix(text[i:], o) {
+				idx, op = i, o
+				break
+			}
+		}
+	}
+	if idx <= 0 {
+		return nil, fmt.Errorf("invalid filter condition %q: expected <field><operator><value>", text)
+	}
+
+	c := &condition{
+		field: strings.TrimSpace(text[:idx]),
+		op:    op,
+		value: unquote(strings.TrimSpace(text[idx+len(op):])),
+	}
+	if c.field == "" {
+		return nil, fmt.Errorf("invalid filter condition %q: missing field", text)
+	}
+
+	if op == "~" || op == "!~" {
+		re, err := regexp.Compile("(?i)" + c.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression in %q: %w", text, err)
+		}
+		c.re = re
+	}
+	return c, nil
+}
+
+func (c *condition) match(get Getter) bool {
+	actual, ok := get(c.field)
+	if !ok {
+		return false
+	}
+
+	switch c.op {
+	case "=":
+		return equal(actual, c.value)
+	case "!=":
+		return !equal(actual, c.value)
+	case "~":
+		return c.re.MatchString(actual)
+	case "!~":
+		return !c.re.MatchString(actual)
+	}
+
+	cmp := compare(actual, c.value)
+	switch c.op {
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	default:
+		return cmp <= 0
+	}
+}
+
+func equal(a, b string) bool {
+	if x, y, ok := numbers(a, b); ok {
+		return x == y
+	}
+	return strings.EqualFold(a, b)
+}
+
+func compare(a, b string) int {
+	if x, y, ok := numbers(a, b); ok {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+func numbers(a, b string) (float64, float64, bool) {
+	x, errA := strconv.ParseFloat(strings.TrimSpace(a), 64)
+	y, errB := strconv.ParseFloat(strings.TrimSpace(b), 64)
+	return x, y, errA == nil && errB == nil
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
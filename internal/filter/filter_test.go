@@ -0,0 +1,109 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+)
+
+func getter(fields map[string]string) Getter {
+	return func(field string) (string, bool) {
+		v, ok := fields[field]
+		return v, ok
+	}
+}
+
+func TestMatch(t *testing.T) {
+	web := map[string]string{
+		"Name":  "web-123",
+		"State": "running",
+		"Type":  "t3.micro",
+		"AZ":    "us-east-1a",
+		"CPU":   "8",
+		"Note":  "a, b",
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"", true},
+		{"State=running", true},
+		{"State=RUNNING", true},
+		{"State!=running", false},
+		{"Type~^t3\\.", true},
+		{"Type!~^t3\\.", false},
+		{"CPU>4", true},
+		{"CPU>=8", true},
+		{"CPU<8", false},
+		{"CPU<=8", true},
+		{"CPU=8.0", true},
+		{"AZ>us-east-1", true},
+		{"Missing=x", false},
+		{"Missing!=x", false},
+		{"State=running,AZ=us-east-1b", false},
+		{"State=running && AZ=us-east-1a", true},
+		{"State=running and AZ=us-east-1a", true},
+		{"State=stopped || AZ=us-east-1a", true},
+		{"State=stopped or AZ=us-east-1b", false},
+		// AND binds tighter than OR
+		{"State=stopped,AZ=us-east-1a || CPU=8", true},
+		{"State=stopped,AZ=us-east-1a || CPU=9", false},
+		{"State=stopped && (AZ=us-east-1a || CPU=8)", false},
+		{"(State=stopped || CPU=8) && AZ=us-east-1a", true},
+		{`Note="a, b"`, true},
+		{`Note='a, b'`, true},
+		{"Name~^web-(1|2)", true},
+		{"Name~^web-[0-9]{2,3}$", true},
+		{"Name~^web-[0-9]{4,5}$", false},
+		{"Name~^web-[0-9]{2,3}$,State=running", true},
+		{"Name~^web-[0-9]{2,3}$,State=stopped", false},
+	}
+
+	for _, tt := range tests {
+		f, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := f.Match(getter(web)); got != tt.want {
+			t.Errorf("Parse(%q).Match = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestFields(t *testing.T) {
+	f, err := Parse("State=running,(Type~t3 || AZ!=us-east-1a)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(f.Fields(), ","); got != "State,Type,AZ" {
+		t.Errorf("Fields = %s, want State,Type,AZ", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		err  string
+	}{
+		{"State", "expected <field><operator><value>"},
+		{"=running", "expected <field><operator><value>"},
+		{`Name="web`, "unterminated quote"},
+		{"Name~[", "invalid regular expression"},
+		{"(State=running", "missing closing parenthesis"},
+		{"State=running)", `unexpected ")"`},
+		{"State=running,", "unexpected end of filter"},
+		{"|| State=running", "unexpected OR"},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.expr)
+		if err == nil {
+			t.Errorf("Parse(%q): expected an error", tt.expr)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Parse(%q) = %v, want an error containing %q", tt.expr, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokCondition tokenKind = iota
+	tokAnd
+	tokOr
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokAnd:
+		return "AND"
+	case tokOr:
+		return "OR"
+	case tokLParen:
+		return `"("`
+	case tokRParen:
+		return `")"`
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == ' ' || s[i] == '\t':
+			i++
+		case s[i] == '(':
+			tokens = append(tokens, token{kind: tokLParen})
+			i++
+		case s[i] == ')':
+			tokens = append(tokens, token{kind: tokRParen})
+			i++
+		case s[i] == ',':
+			tokens = append(tokens, token{kind: tokAnd})
+			i++
+		case strings.HasPrefix(s[i:], "&&"):
+			tokens = append(tokens, token{kind: tokAnd})
+			i += 2
+		case strings.HasPrefix(s[i:], "||"):
+			tokens = append(tokens, token{kind: tokOr})
+			i += 2
+		case keywordAt(s, i, "and"):
+			tokens = append(tokens, token{kind: tokAnd})
+			i += 3
+		case keywordAt(s, i, "or"):
+			tokens = append(tokens, token{kind: tokOr})
+			i += 2
+		default:
+			n, err := scanCondition(s[i:])
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokCondition, text: strings.TrimSpace(s[i : i+n])})
+			i += n
+		}
+	}
+	return tokens, nil
+}
+
+// scanCondition returns the length of the condition at the start of s. It
+// ends at a top level separator or at a closing parenthesis that does not
+// belong to the value itself, e.g. a regular expression group. Separators
+// within braces belong to the value too, e.g. the {2,3} quantifier
+func scanCondition(s string) (int, error) {
+	var quote byte
+	depth, braces := 0, 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth == 0 {
+				return i, nil
+			}
+			depth--
+		case c == '{':
+			braces++
+		case c == '}' && braces > 0:
+			braces--
+		case depth > 0 || braces > 0:
+		case c == ',' || strings.HasPrefix(s[i:], "&&") || strings.HasPrefix(s[i:], "||"):
+			return i, nil
+		case c == ' ' || c == '\t':
+			j := i
+			for j < len(s) && (s[j] == ' ' || s[j] == '\t') {
+				j++
+			}
+			if keywordAt(s, j, "and") || keywordAt(s, j, "or") {
+				return i, nil
+			}
+		}
+	}
+	if quote != 0 {
+		return 0, fmt.Errorf("unterminated quote in filter: %s", s)
+	}
+	return len(s), nil
+}
+
+// keywordAt reports whether the word kw (case insensitive) starts at s[i]
+// as a standalone word
+func keywordAt(s string, i int, kw string) bool {
+	if i > 0 && s[i-1] != ' ' && s[i-1] != '\t' && s[i-1] != ')' {
+		return false
+	}
+	end := i + len(kw)
+	if end > len(s) || !strings.EqualFold(s[i:end], kw) {
+		return false
+	}
+	return end < len(s) && (s[end] == ' ' || s[end] == '\t' || s[end] == '(')
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	filter *Filter
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *parser) parseOr() (node, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := orNode{first}
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind != tokOr {
+			break
+		}
+		p.pos++
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, next)
+	}
+
+	if len(nodes) == 1 {
+		return first, nil
+	}
+	return nodes, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	first, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := andNode{first}
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind != tokAnd {
+			break
+		}
+		p.pos++
+		next, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, next)
+	}
+
+	if len(nodes) == 1 {
+		return first, nil
+	}
+	return nodes, nil
+}
+
+func (p *parser) parseFactor() (node, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of filter")
+	}
+
+	switch t.kind {
+	case tokLParen:
+		p.pos++
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t, ok := p.peek(); !ok || t.kind != tokRParen {
+			return nil, fmt.Errorf("missing closing parenthesis in filter")
+		}
+		p.pos++
+		return n, nil
+
+	case tokCondition:
+		p.pos++
+		c, err := parseCondition(t.text)
+		if err != nil {
+			return nil, err
+		}
+		p.filter.fields = append(p.filter.fields, c.field)
+		return c, nil
+	}
+
+	return nil, fmt.Errorf("unexpected %s in filter", t)
+}
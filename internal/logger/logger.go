@@ -2,6 +2,7 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"sync"
 	"time"
@@ -26,9 +27,17 @@ var (
 	warnColor    = color.New(color.FgYellow)
 	errorColor   = color.New(color.FgHiRed, color.Bold)
 
-	mu sync.Mutex
+	mu  sync.Mutex
+	out io.Writer = os.Stderr
 )
 
+// SetOutput redirects the log lines, e.g. away from a full-screen terminal UI
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	out = w
+}
+
 func log(level Level, msg string, a ...any) {
 	mu.Lock()
 	defer mu.Unlock()
@@ -55,9 +64,9 @@ func log(level Level, msg string, a ...any) {
 		c = errorColor
 	}
 
-	fmt.Fprintf(os.Stderr, "%s ", color.New(color.FgHiBlack).Sprint(timestamp))
-	c.Fprintf(os.Stderr, "%-9s", prefix)
-	fmt.Fprintf(os.Stderr, " %s\n", fmt.Sprintf(msg, a...))
+	fmt.Fprintf(out, "%s ", color.New(color.FgHiBlack).Sprint(timestamp))
+	c.Fprintf(out, "%-9s", prefix)
+	fmt.Fprintf(out, " %s\n", fmt.Sprintf(msg, a...))
 }
 
 func Debug(msg string, a ...any)   { log(LevelDebug, msg, a...) }
@@ -10,6 +10,7 @@ type CloudFrontDistribution struct {
 	Origins       map[string]string // ID -> DomainName
 	DefaultOrigin string
 	Behaviors     []CloudFrontBehavior
+	Tags          map[string]string
 }
 
 type CloudFrontBehavior struct {
@@ -10,18 +10,67 @@ const (
 	NodeOrigin      = "Origin"
 	NodeDNS         = "DNS"
 	NodeRDS         = "RDS"
+	NodeRDSInstance = "RDS Instance"
+	NodeListener    = "Listener"
+	NodeRule        = "Rule"
+	NodeTarget      = "Target"
+	NodeInstance    = "Instance"
 )
 
+const (
+	StatusHealthy   = "healthy"
+	StatusUnhealthy = "unhealthy"
+	// StatusUnavailable marks the nodes whose health is only known live,
+	// traced offline or when the health lookup failed
+	StatusUnavailable = "unavailable"
+)
+
+// TraceNode is a hop of a trace. The JSON field names are part of the
+// output of astat domain trace, scripts rely on them
 type TraceNode struct {
-	Type     string
-	Name     string
-	ID       string
-	Value    string
-	Status   string
-	Children []TraceNode
+	Type     string      `json:"Type"`
+	Name     string      `json:"Name"`
+	ID       string      `json:"ID,omitempty"`
+	Value    string      `json:"Value,omitempty"`
+	Status   string      `json:"Status,omitempty"`
+	Children []TraceNode `json:"Children,omitempty"`
 }
 
 type TraceResult struct {
-	Domain string
-	Hops   []TraceNode
+	Domain string      `json:"Domain"`
+	Hops   []TraceNode `json:"Hops"`
+	// Cached is set when the trace was resolved from the local cache only
+	Cached bool `json:"Cached"`
+	// Warnings name the lookups that failed, leaving the trace possibly
+	// incomplete
+	Warnings []string `json:"Warnings,omitempty"`
+}
+
+// Unhealthy returns the unhealthy nodes of the trace with no healthy
+// alternative. Nodes of the same type under the same parent, such as the
+// targets of a target group, share the traffic: one of them being unhealthy
+// only matters when none of the others is healthy
+func (r TraceResult) Unhealthy() []TraceNode {
+	var nodes []TraceNode
+	var walk func([]TraceNode)
+	walk = func(hops []TraceNode) {
+		for _, n := range hops {
+			if n.Status == StatusUnhealthy && !hasHealthy(hops, n.Type) {
+				nodes = append(nodes, n)
+			}
+			walk(n.Children)
+		}
+	}
+	walk(r.Hops)
+	return nodes
+}
+
+// hasHealthy reports whether one of the nodes of a type is healthy
+func hasHealthy(nodes []TraceNode, nodeType string) bool {
+	for _, n := range nodes {
+		if n.Type == nodeType && n.Status == StatusHealthy {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,88 @@
+package model
+
+import "testing"
+
+func targetGroup(status string, targets ...string) TraceNode {
+	tg := TraceNode{Type: NodeTargetGroup, Name: "web", Status: status}
+	for i, s := range targets {
+		tg.Children = append(tg.Children, TraceNode{Type: NodeTarget, Name: string(rune('a' + i)), Status: s})
+	}
+	return tg
+}
+
+func trace(nodes ...TraceNode) TraceResult {
+	return TraceResult{Domain: "example.com", Hops: []TraceNode{{
+		Type: NodeRoute53,
+		Name: "example.com",
+		Children: []TraceNode{{
+			Type:     NodeALB,
+			Name:     "web-alb",
+			Children: []TraceNode{{Type: NodeListener, Name: "HTTPS:443", Children: nodes}},
+		}},
+	}}}
+}
+
+func TestUnhealthy(t *testing.T) {
+	tests := []struct {
+		name   string
+		result TraceResult
+		want   []string
+	}{
+		{
+			name:   "all healthy",
+			result: trace(targetGroup(StatusHealthy, StatusHealthy, StatusHealthy)),
+		},
+		{
+			name:   "unhealthy target next to a healthy one",
+			result: trace(targetGroup(StatusHealthy, StatusHealthy, StatusUnhealthy)),
+		},
+		{
+			name:   "no healthy target",
+			result: trace(targetGroup(StatusUnhealthy, StatusUnhealthy, StatusUnhealthy)),
+			want:   []string{NodeTargetGroup, NodeTarget, NodeTarget},
+		},
+		{
+			name:   "empty target group",
+			result: trace(targetGroup(StatusUnhealthy)),
+			want:   []string{NodeTargetGroup},
+		},
+		{
+			name:   "health unavailable",
+			result: trace(targetGroup(StatusUnavailable)),
+		},
+		{
+			name: "unhealthy instances of a classic load balancer",
+			result: trace(
+				TraceNode{Type: NodeInstance, Name: "i-1", Status: StatusUnhealthy},
+				TraceNode{Type: NodeInstance, Name: "i-2", Status: StatusHealthy},
+			),
+		},
+		{
+			name: "unhealthy RDS cluster",
+			result: TraceResult{Hops: []TraceNode{{
+				Type:   NodeRDS,
+				Status: StatusUnhealthy,
+				Children: []TraceNode{
+					{Type: NodeRDSInstance, Status: StatusHealthy},
+				},
+			}}},
+			want: []string{NodeRDS},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, n := range tt.result.Unhealthy() {
+				got = append(got, n.Type)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Unhealthy() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Unhealthy()[%d] = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
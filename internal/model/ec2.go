@@ -9,4 +9,18 @@ type EC2Instance struct {
 	PrivateIP    string `header:"Private IP"`
 	PublicIP     string `header:"Public IP"`
 	LaunchTime   string `header:"Launch Time"`
+
+	VPCID              string
+	SubnetID           string
+	SecurityGroups     []string // IDs
+	SecurityGroupNames []string
+	IAMProfile         string // instance profile name
+	ImageID            string
+	KeyName            string
+	Platform           string
+	Architecture       string
+	Lifecycle          string // on-demand, spot, scheduled or capacity-block
+	ENIs               []string
+
+	Tags map[string]string
 }
@@ -7,6 +7,9 @@ type LoadBalancer struct {
 	CreatedAt string `header:"Created At"`
 	DNSName   string `header:"DNS"`
 	ARN       string // For v2
+	Listeners []Listener
+	Instances []string // For classic, the registered instance IDs
+	Tags      map[string]string
 }
 
 type Listener struct {
@@ -14,6 +17,7 @@ type Listener struct {
 	Protocol       string
 	Port           int32
 	DefaultActions []Action
+	Rules          []Rule // For application load balancers
 }
 
 type Rule struct {
@@ -35,12 +39,14 @@ type Action struct {
 }
 
 type TargetGroup struct {
-	Name            string
-	Protocol        string
-	Port            int32
-	TargetType      string
-	LoadBalancerARN string
-	ARN             string
+	Name             string `header:"Name"`
+	Protocol         string `header:"Protocol"`
+	Port             int32  `header:"Port"`
+	TargetType       string `header:"Target Type"`
+	VPCID            string `header:"VPC"`
+	LoadBalancerARNs []string
+	ARN              string
+	Tags             map[string]string
 }
 
 type InstanceHealth struct {
@@ -6,4 +6,5 @@ type LambdaFunction struct {
 	LastModified string `header:"Last Modified"`
 	Memory       string `header:"Memory (MB)"`
 	Timeout      string `header:"Timeout (s)"`
+	Tags         map[string]string
 }
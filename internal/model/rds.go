@@ -12,6 +12,7 @@ type RDSCluster struct {
 	Endpoint          string `header:""`
 	ReaderEndpoint    string `header:""`
 	CreateTime        string `header:"Created At"`
+	Tags              map[string]string
 }
 
 type RDSInstance struct {
@@ -24,4 +25,5 @@ type RDSInstance struct {
 	Endpoint           string `header:""`
 	InstanceClass      string `header:"Class"`
 	AvailabilityZone   string `header:"AZ"`
+	Tags               map[string]string
 }
@@ -5,6 +5,7 @@ type Route53HostedZone struct {
 	Name    string `header:"Name"`
 	Type    string `header:"Type"`
 	Records string `header:"Records"`
+	Tags    map[string]string
 }
 
 type Route53Record struct {
@@ -13,4 +14,7 @@ type Route53Record struct {
 	Type     string `header:"Type"`
 	TTL      string `header:"TTL"`
 	Value    string `header:"Value"`
+	// SetIdentifier tells apart the records of a weighted, latency,
+	// failover or other routing policy sharing a name and type
+	SetIdentifier string
 }
@@ -4,4 +4,5 @@ type S3Bucket struct {
 	Name         string `header:"Name"`
 	Region       string `header:"Region"`
 	CreationDate string `header:"Creation Date"`
+	Tags         map[string]string
 }
@@ -3,4 +3,5 @@ package model
 type SQSQueue struct {
 	Name string `header:"Name"`
 	Type string `header:"Type"`
+	Tags map[string]string
 }
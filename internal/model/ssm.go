@@ -5,4 +5,5 @@ type SSMParameter struct {
 	Type         string `header:"Type"`
 	LastModified string `header:"Last Modified"`
 	ModifiedBy   string `header:"Modified By"`
+	Tags         map[string]string
 }
@@ -0,0 +1,76 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// PrintCSV prints a header line followed by the rows as RFC 4180 CSV
+func PrintCSV(headers []string, rows [][]string) error {
+	w := csv.NewWriter(os.Stdout)
+	if err := w.Write(headers); err != nil {
+		return err
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return err
+	}
+	return w.Error()
+}
+
+// tsvReplacer keeps every cell on one line and in one column
+var tsvReplacer = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+
+// PrintTSV prints a header line followed by the rows, tab separated
+func PrintTSV(headers []string, rows [][]string) error {
+	return printLines(os.Stdout, headers, rows, func(cells []string) string {
+		out := make([]string, len(cells))
+		for i, c := range cells {
+			out[i] = tsvReplacer.Replace(c)
+		}
+		return strings.Join(out, "\t")
+	})
+}
+
+// markdownReplacer escapes the characters that would break a table cell
+var markdownReplacer = strings.NewReplacer("|", `\|`, "\r", " ", "\n", "<br>")
+
+// PrintMarkdown prints the rows as a GitHub flavored markdown table
+func PrintMarkdown(headers []string, rows [][]string) error {
+	line := func(cells []string) string {
+		out := make([]string, len(cells))
+		for i, c := range cells {
+			out[i] = markdownReplacer.Replace(c)
+		}
+		return "| " + strings.Join(out, " | ") + " |"
+	}
+
+	separator := make([]string, len(headers))
+	for i := range separator {
+		separator[i] = "---"
+	}
+
+	if _, err := fmt.Fprintln(os.Stdout, line(headers)); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(os.Stdout, "|"+strings.Join(separator, "|")+"|"); err != nil {
+		return err
+	}
+	return printLines(os.Stdout, nil, rows, line)
+}
+
+func printLines(w io.Writer, headers []string, rows [][]string, line func([]string) string) error {
+	if headers != nil {
+		if _, err := fmt.Fprintln(w, line(headers)); err != nil {
+			return err
+		}
+	}
+	for _, row := range rows {
+		if _, err := fmt.Fprintln(w, line(row)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Shape is the shape a graph node is drawn with
+type Shape string
+
+const (
+	ShapeBox           Shape = "box"
+	ShapeRounded       Shape = "rounded"
+	ShapeEllipse       Shape = "ellipse"
+	ShapeCircle        Shape = "circle"
+	ShapeHexagon       Shape = "hexagon"
+	ShapeParallelogram Shape = "parallelogram"
+	ShapeDiamond       Shape = "diamond"
+	ShapeCylinder      Shape = "cylinder"
+)
+
+// Graph is a directed graph, printed by PrintDOT and PrintMermaid
+type Graph struct {
+	Nodes []GraphNode
+	Edges []GraphEdge
+}
+
+// GraphNode is a node of a Graph. Its label may span several lines
+type GraphNode struct {
+	ID    string
+	Label string
+	Shape Shape
+}
+
+// GraphEdge links two nodes by ID, drawn in Color when set, e.g. #2e7d32
+type GraphEdge struct {
+	From  string
+	To    string
+	Color string
+}
+
+// dotShapes maps the shapes to Graphviz shapes and styles
+var dotShapes = map[Shape]string{
+	ShapeBox:           `shape=box`,
+	ShapeRounded:       `shape=box, style=rounded`,
+	ShapeEllipse:       `shape=ellipse`,
+	ShapeCircle:        `shape=circle`,
+	ShapeHexagon:       `shape=hexagon`,
+	ShapeParallelogram: `shape=parallelogram`,
+	ShapeDiamond:       `shape=diamond`,
+	ShapeCylinder:      `shape=cylinder`,
+}
+
+// PrintDOT prints the graph in the Graphviz DOT language
+func PrintDOT(g Graph) error {
+	var b strings.Builder
+	b.WriteString("digraph astat {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [fontname=\"Helvetica\", fontsize=10];\n")
+	for _, n := range g.Nodes {
+		attrs, ok := dotShapes[n.Shape]
+		if !ok {
+			attrs = dotShapes[ShapeBox]
+		}
+		fmt.Fprintf(&b, "  %s [label=%s, %s];\n", dotQuote(n.ID), dotQuote(n.Label), attrs)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s", dotQuote(e.From), dotQuote(e.To))
+		if e.Color != "" {
+			fmt.Fprintf(&b, " [color=%s]", dotQuote(e.Color))
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+
+	_, err := os.Stdout.WriteString(b.String())
+	return err
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// mermaidShapes holds the brackets wrapping a label for each shape
+var mermaidShapes = map[Shape][2]string{
+	ShapeBox:           {"[", "]"},
+	ShapeRounded:       {"(", ")"},
+	ShapeEllipse:       {"([", "])"},
+	ShapeCircle:        {"((", "))"},
+	ShapeHexagon:       {"{{", "}}"},
+	ShapeParallelogram: {"[/", "/]"},
+	ShapeDiamond:       {"{", "}"},
+	ShapeCylinder:      {"[(", ")]"},
+}
+
+// PrintMermaid prints the graph as a Mermaid flowchart
+func PrintMermaid(g Graph) error {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, n := range g.Nodes {
+		brackets, ok := mermaidShapes[n.Shape]
+		if !ok {
+			brackets = mermaidShapes[ShapeBox]
+		}
+		fmt.Fprintf(&b, "  %s%s%s%s\n", n.ID, brackets[0], mermaidQuote(n.Label), brackets[1])
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s --> %s\n", e.From, e.To)
+	}
+	// Links are styled by their index, in the order they were declared
+	for i, e := range g.Edges {
+		if e.Color != "" {
+			fmt.Fprintf(&b, "  linkStyle %d stroke:%s\n", i, e.Color)
+		}
+	}
+
+	_, err := os.Stdout.WriteString(b.String())
+	return err
+}
+
+func mermaidQuote(s string) string {
+	return `"` + strings.NewReplacer(`"`, "#quot;", "\n", "<br/>").Replace(s) + `"`
+}
@@ -10,3 +10,19 @@ func PrintJSON(v any) error {
 	enc.SetIndent("", "  ")
 	return enc.Encode(v)
 }
+
+// PrintNDJSON prints every element of a slice as a JSON document on its own
+// line, any other value as a single line
+func PrintNDJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	items, ok := v.([]any)
+	if !ok {
+		return enc.Encode(v)
+	}
+	for _, item := range items {
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
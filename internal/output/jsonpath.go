@@ -0,0 +1,609 @@
+package output
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PrintJSONPath prints v through a kubectl style JSONPath template, e.g.
+//
+//	{[*].InstanceID}
+//	{range [*]}{.InstanceID}{"\t"}{.PrivateIP}{"\n"}{end}
+//	{[?(@.State=="running")].Name}
+//
+// The template is evaluated over the JSON representation of v. Accessing a
+// field of a list accesses it on every element, and a template without any
+// braces is taken as a single path
+func PrintJSONPath(text string, v any) error {
+	out, err := renderJSONPath(text, v)
+	if err != nil {
+		return err
+	}
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	_, err = os.Stdout.WriteString(out)
+	return err
+}
+
+// renderJSONPath evaluates a JSONPath template over the JSON representation
+// of v
+func renderJSONPath(text string, v any) (string, error) {
+	jp, err := parseJSONPath(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid jsonpath: %w", err)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	var root any
+	if err := json.Unmarshal(data, &root); err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	jp.execute(&out, jp.nodes, root, root)
+	return out.String(), nil
+}
+
+// jsonPath is a parsed template: literal text mixed with {path}, {"string"}
+// and {range path}...{end} actions
+type jsonPath struct {
+	nodes []jpNode
+}
+
+type jpNode any
+
+type jpText string
+
+type jpRange struct {
+	expr jpExpr
+	body []jpNode
+}
+
+// jpExpr is a path, evaluated from the root ($) or the current element (@)
+type jpExpr struct {
+	root  bool
+	steps []jpStep
+}
+
+type jpStepKind int
+
+const (
+	stepField jpStepKind = iota
+	stepWildcard
+	stepIndex
+	stepSlice
+	stepRecursive
+	stepFilter
+)
+
+type jpStep struct {
+	kind       jpStepKind
+	name       string
+	index      int
+	start, end *int
+	filter     *jpFilter
+}
+
+// jpFilter is a [?(@.field op value)] condition, or an existence check when
+// op is empty
+type jpFilter struct {
+	lhs     jpExpr
+	op      string
+	rhs     any
+	rhsExpr *jpExpr
+}
+
+func parseJSONPath(text string) (*jsonPath, error) {
+	if !strings.Contains(text, "{") {
+		text = "{" + text + "}"
+	}
+	nodes, _, err := parseJPNodes(text, false)
+	if err != nil {
+		return nil, err
+	}
+	return &jsonPath{nodes: nodes}, nil
+}
+
+// parseJPNodes parses actions up to the end of text, or up to the {end}
+// closing the current range, returning the text left after it
+func parseJPNodes(text string, inRange bool) ([]jpNode, string, error) {
+	var nodes []jpNode
+	for text != "" {
+		open := strings.IndexByte(text, '{')
+		if open < 0 {
+			nodes = append(nodes, jpText(text))
+			break
+		}
+		if open > 0 {
+			nodes = append(nodes, jpText(text[:open]))
+		}
+
+		closing := closingIndex(text[open:], '{', '}')
+		if closing < 0 {
+			return nil, "", fmt.Errorf("unclosed action in %q", text[open:])
+		}
+		action := strings.TrimSpace(text[open+1 : open+closing])
+		text = text[open+closing+1:]
+
+		switch {
+		case action == "end":
+			if !inRange {
+				return nil, "", fmt.Errorf("{end} without {range}")
+			}
+			return nodes, text, nil
+
+		case strings.HasPrefix(action, "range "):
+			expr, err := parseJPExpr(strings.TrimSpace(strings.TrimPrefix(action, "range ")))
+			if err != nil {
+				return nil, "", err
+			}
+			body, rest, err := parseJPNodes(text, true)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, jpRange{expr: expr, body: body})
+			text = rest
+
+		case strings.HasPrefix(action, `"`):
+			s, err := strconv.Unquote(action)
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid string %s", action)
+			}
+			nodes = append(nodes, jpText(s))
+
+		default:
+			expr, err := parseJPExpr(action)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, expr)
+		}
+	}
+
+	if inRange {
+		return nil, "", fmt.Errorf("{range} without {end}")
+	}
+	return nodes, "", nil
+}
+
+// closingIndex returns the index of the delimiter closing the one at s[0],
+// skipping quoted strings and nested pairs, or -1
+func closingIndex(s string, open, close byte) int {
+	var quote byte
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == open:
+			depth++
+		case c == close:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func parseJPExpr(s string) (jpExpr, error) {
+	var e jpExpr
+	switch {
+	case strings.HasPrefix(s, "$"):
+		e.root = true
+		s = s[1:]
+	case strings.HasPrefix(s, "@"):
+		s = s[1:]
+	}
+
+	for s != "" {
+		switch {
+		case strings.HasPrefix(s, ".."):
+			s = s[2:]
+			name, rest := jpIdent(s)
+			if strings.HasPrefix(s, "*") {
+				name, rest = "*", s[1:]
+			}
+			if name == "" {
+				return e, fmt.Errorf("expected a field name after '..'")
+			}
+			e.steps = append(e.steps, jpStep{kind: stepRecursive, name: name})
+			s = rest
+
+		case s[0] == '.':
+			s = s[1:]
+			if s == "" || s[0] == '[' {
+				continue
+			}
+			if s[0] == '*' {
+				e.steps = append(e.steps, jpStep{kind: stepWildcard})
+				s = s[1:]
+				continue
+			}
+			name, rest := jpIdent(s)
+			if name == "" {
+				return e, fmt.Errorf("expected a field name at %q", s)
+			}
+			e.steps = append(e.steps, jpStep{kind: stepField, name: name})
+			s = rest
+
+		case s[0] == '[':
+			end := closingIndex(s, '[', ']')
+			if end < 0 {
+				return e, fmt.Errorf("unclosed '[' in %q", s)
+			}
+			step, err := parseJPBracket(strings.TrimSpace(s[1:end]))
+			if err != nil {
+				return e, err
+			}
+			e.steps = append(e.steps, step)
+			s = s[end+1:]
+
+		default:
+			name, rest := jpIdent(s)
+			if name == "" {
+				return e, fmt.Errorf("unexpected %q", s)
+			}
+			e.steps = append(e.steps, jpStep{kind: stepField, name: name})
+			s = rest
+		}
+	}
+	return e, nil
+}
+
+func jpIdent(s string) (string, string) {
+	i := strings.IndexAny(s, ".[")
+	if i < 0 {
+		return strings.TrimSpace(s), ""
+	}
+	return strings.TrimSpace(s[:i]), s[i:]
+}
+
+func parseJPBracket(content string) (jpStep, error) {
+	switch {
+	case content == "*":
+		return jpStep{kind: stepWildcard}, nil
+
+	case len(content) >= 2 && (content[0] == '\'' || content[0] == '"') && content[len(content)-1] == content[0]:
+		return jpStep{kind: stepField, name: content[1 : len(content)-1]}, nil
+
+	case strings.HasPrefix(content, "?(") && strings.HasSuffix(content, ")"):
+		f, err := parseJPFilter(strings.TrimSpace(content[2 : len(content)-1]))
+		if err != nil {
+			return jpStep{}, err
+		}
+		return jpStep{kind: stepFilter, filter: f}, nil
+
+	case strings.Contains(content, ":"):
+		from, to, _ := strings.Cut(content, ":")
+		step := jpStep{kind: stepSlice}
+		for _, bound := range []struct {
+			text string
+			dst  **int
+		}{{from, &step.start}, {to, &step.end}} {
+			if t := strings.TrimSpace(bound.text); t != "" {
+				n, err := strconv.Atoi(t)
+				if err != nil {
+					return jpStep{}, fmt.Errorf("invalid slice [%s]", content)
+				}
+				*bound.dst = &n
+			}
+		}
+		return step, nil
+	}
+
+	n, err := strconv.Atoi(content)
+	if err != nil {
+		return jpStep{}, fmt.Errorf("invalid subscript [%s]", content)
+	}
+	return jpStep{kind: stepIndex, index: n}, nil
+}
+
+// jpOperators is ordered so that two character operators are tried first
+var jpOperators = []string{"==", "!=", ">=", "<=", ">", "<"}
+
+func parseJPFilter(s string) (*jpFilter, error) {
+	idx, op := -1, ""
+	var quote byte
+	for i := 0; i < len(s) && idx < 0; i++ {
+		c := s[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if c == '"' || c == '\'' {
+			quote = c
+			continue
+		}
+		for _, o := range jpOperators {
+			if strings.HasPrefix(s[i:], o) {
+				idx, op = i, o
+				break
+			}
+		}
+	}
+
+	if idx < 0 {
+		lhs, err := parseJPExpr(s)
+		if err != nil {
+			return nil, err
+		}
+		return &jpFilter{lhs: lhs}, nil
+	}
+
+	lhs, err := parseJPExpr(strings.TrimSpace(s[:idx]))
+	if err != nil {
+		return nil, err
+	}
+	f := &jpFilter{lhs: lhs, op: op}
+
+	rhs := strings.TrimSpace(s[idx+len(op):])
+	switch {
+	case len(rhs) >= 2 && (rhs[0] == '\'' || rhs[0] == '"') && rhs[len(rhs)-1] == rhs[0]:
+		f.rhs = rhs[1 : len(rhs)-1]
+	case rhs == "true" || rhs == "false":
+		f.rhs = rhs == "true"
+	case strings.HasPrefix(rhs, "@") || strings.HasPrefix(rhs, "$"):
+		expr, err := parseJPExpr(rhs)
+		if err != nil {
+			return nil, err
+		}
+		f.rhsExpr = &expr
+	default:
+		n, err := strconv.ParseFloat(rhs, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter value %q, quote strings", rhs)
+		}
+		f.rhs = n
+	}
+	return f, nil
+}
+
+func (jp *jsonPath) execute(out *strings.Builder, nodes []jpNode, root, current any) {
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case jpText:
+			out.WriteString(string(n))
+
+		case jpExpr:
+			results := n.eval(root, current)
+			strs := make([]string, 0, len(results))
+			for _, r := range results {
+				strs = append(strs, jpString(r))
+			}
+			out.WriteString(strings.Join(strs, " "))
+
+		case jpRange:
+			results := n.expr.eval(root, current)
+			if len(results) == 1 {
+				if list, ok := results[0].([]any); ok {
+					results = list
+				}
+			}
+			for _, r := range results {
+				// A null field, e.g. an empty list, has nothing to range over
+				if r != nil {
+					jp.execute(out, n.body, root, r)
+				}
+			}
+		}
+	}
+}
+
+func (e jpExpr) eval(root, current any) []any {
+	values := []any{current}
+	if e.root {
+		values = []any{root}
+	}
+	for _, step := range e.steps {
+		values = step.apply(values, root)
+	}
+	return values
+}
+
+func (s jpStep) apply(values []any, root any) []any {
+	var out []any
+	for _, v := range values {
+		switch s.kind {
+		case stepField:
+			out = append(out, jpField(v, s.name)...)
+
+		case stepWildcard:
+			out = append(out, jpChildren(v)...)
+
+		case stepIndex:
+			if list, ok := v.([]any); ok {
+				i := s.index
+				if i < 0 {
+					i += len(list)
+				}
+				if i >= 0 && i < len(list) {
+					out = append(out, list[i])
+				}
+			}
+
+		case stepSlice:
+			if list, ok := v.([]any); ok {
+				start, end := 0, len(list)
+				if s.start != nil {
+					start = clampIndex(*s.start, len(list))
+				}
+				if s.end != nil {
+					end = clampIndex(*s.end, len(list))
+				}
+				if start < end {
+					out = append(out, list[start:end]...)
+				}
+			}
+
+		case stepRecursive:
+			out = append(out, jpDescendants(v, s.name)...)
+
+		case stepFilter:
+			for _, child := range jpChildren(v) {
+				if s.filter.match(root, child) {
+					out = append(out, child)
+				}
+			}
+		}
+	}
+	return out
+}
+
+func clampIndex(i, n int) int {
+	if i < 0 {
+		i += n
+	}
+	return max(0, min(i, n))
+}
+
+// jpField returns the field of an object, or of every element of a list
+func jpField(v any, name string) []any {
+	switch t := v.(type) {
+	case map[string]any:
+		if x, ok := t[name]; ok {
+			return []any{x}
+		}
+	case []any:
+		var out []any
+		for _, e := range t {
+			out = append(out, jpField(e, name)...)
+		}
+		return out
+	}
+	return nil
+}
+
+// jpChildren returns the elements of a list, or the values of an object in
+// key order
+func jpChildren(v any) []any {
+	switch t := v.(type) {
+	case []any:
+		return t
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+
+		out := make([]any, 0, len(keys))
+		for _, k := range keys {
+			out = append(out, t[k])
+		}
+		return out
+	}
+	return nil
+}
+
+// jpDescendants returns every value below v held by a field with the given
+// name, or every value when name is "*"
+func jpDescendants(v any, name string) []any {
+	var out []any
+	switch t := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+
+		for _, k := range keys {
+			if name == "*" || k == name {
+				out = append(out, t[k])
+			}
+			out = append(out, jpDescendants(t[k], name)...)
+		}
+	case []any:
+		for _, e := range t {
+			if name == "*" {
+				out = append(out, e)
+			}
+			out = append(out, jpDescendants(e, name)...)
+		}
+	}
+	return out
+}
+
+func (f *jpFilter) match(root, current any) bool {
+	lhs := f.lhs.eval(root, current)
+	if f.op == "" {
+		return len(lhs) > 0 && lhs[0] != nil && lhs[0] != false && lhs[0] != ""
+	}
+	if len(lhs) == 0 {
+		return false
+	}
+
+	rhs := f.rhs
+	if f.rhsExpr != nil {
+		values := f.rhsExpr.eval(root, current)
+		if len(values) == 0 {
+			return false
+		}
+		rhs = values[0]
+	}
+
+	c := jpCompare(lhs[0], rhs)
+	switch f.op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	default:
+		return c <= 0
+	}
+}
+
+// jpCompare compares numbers numerically, including numeric strings, and
+// anything else by its string form
+func jpCompare(a, b any) int {
+	x, errA := strconv.ParseFloat(jpString(a), 64)
+	y, errB := strconv.ParseFloat(jpString(b), 64)
+	if errA == nil && errB == nil {
+		return cmp.Compare(x, y)
+	}
+	return strings.Compare(jpString(a), jpString(b))
+}
+
+func jpString(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
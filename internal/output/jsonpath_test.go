@@ -0,0 +1,102 @@
+package output
+
+import (
+	"strings"
+	"testing"
+)
+
+type testInstance struct {
+	InstanceID string
+	Name       string
+	State      string
+	CPU        int
+	Public     bool
+	Tags       map[string]string
+	SGs        []string
+}
+
+var testInstances = []testInstance{
+	{InstanceID: "i-1", Name: "web", State: "running", CPU: 2, Public: true, Tags: map[string]string{"env": "prod", "team": "web"}, SGs: []string{"sg-1", "sg-2"}},
+	{InstanceID: "i-2", Name: "api", State: "stopped", CPU: 4, Tags: map[string]string{"env": "dev"}},
+	{InstanceID: "i-3", Name: "db", State: "running", CPU: 8, Tags: map[string]string{"env": "prod"}, SGs: []string{"sg-3"}},
+}
+
+func TestRenderJSONPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"path without braces", "[*].InstanceID", "i-1 i-2 i-3"},
+		{"wildcard", "{[*].InstanceID}", "i-1 i-2 i-3"},
+		{"root", "{$[0].Name}", "web"},
+		{"field of a list", "{.Name}", "web api db"},
+		{"index", "{[1].Name}", "api"},
+		{"negative index", "{[-1].Name}", "db"},
+		{"index out of range", "{[5].Name}", ""},
+		{"slice", "{[0:2].Name}", "web api"},
+		{"open slice", "{[1:].Name}", "api db"},
+		{"negative slice", "{[-2:].Name}", "api db"},
+		{"quoted key", "{[0].Tags['env']}", "prod"},
+		{"nested field", "{[*].Tags.env}", "prod dev prod"},
+		{"object wildcard", "{[0].Tags.*}", "prod web"},
+		{"recursive descent", "{..env}", "prod dev prod"},
+		{"missing key", "{[*].Missing}", ""},
+		{"missing nested key", "{[0].Tags.owner}", ""},
+		{"string filter", `{[?(@.State=="running")].Name}`, "web db"},
+		{"single quoted filter", `{[?(@.State!='running')].Name}`, "api"},
+		{"numeric filter", "{[?(@.CPU>=4)].Name}", "api db"},
+		{"numeric less than", "{[?(@.CPU<4)].Name}", "web"},
+		{"bool filter", "{[?(@.Public==true)].Name}", "web"},
+		{"existence filter", "{[?(@.SGs)].Name}", "web db"},
+		{"nested filter", `{[?(@.Tags.env=="dev")].InstanceID}`, "i-2"},
+		{"filter on root", `{[?(@.CPU>$[0].CPU)].Name}`, "api db"},
+		{"string literal", `{[0].Name}{"\t"}{[0].State}`, "web\trunning"},
+		{"literal text", "id={[0].InstanceID}!", "id=i-1!"},
+		{"list value", "{[0].SGs}", `["sg-1","sg-2"]`},
+		{"range", `{range [*]}{.InstanceID}{"\t"}{.Name}{"\n"}{end}`, "i-1\tweb\ni-2\tapi\ni-3\tdb\n"},
+		{"range over filter", `{range [?(@.State=="running")]}{.Name},{end}`, "web,db,"},
+		{"nested range", `{range [*]}{.Name}:{range .SGs}{@} {end};{end}`, "web:sg-1 sg-2 ;api:;db:sg-3 ;"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderJSONPath(tt.template, testInstances)
+			if err != nil {
+				t.Fatalf("renderJSONPath(%q): %v", tt.template, err)
+			}
+			if got != tt.want {
+				t.Errorf("renderJSONPath(%q) = %q, want %q", tt.template, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderJSONPathErrors(t *testing.T) {
+	tests := []struct {
+		template string
+		err      string
+	}{
+		{"{[0].Name", "unclosed action"},
+		{"{range [*]}{.Name}", "{range} without {end}"},
+		{"{.Name}{end}", "{end} without {range}"},
+		{"{[abc]}", "invalid subscript [abc]"},
+		{"{[1:x]}", "invalid slice [1:x]"},
+		{"{[0.Name}", "unclosed '['"},
+		{"{..}", "expected a field name after '..'"},
+		{`{"unterminated}`, "unclosed action"},
+		{`{"bad\q"}`, "invalid string"},
+		{"{[?(@.State==running)]}", "invalid filter value"},
+	}
+
+	for _, tt := range tests {
+		_, err := renderJSONPath(tt.template, testInstances)
+		if err == nil {
+			t.Errorf("renderJSONPath(%q): expected an error", tt.template)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("renderJSONPath(%q) = %v, want an error containing %q", tt.template, err, tt.err)
+		}
+	}
+}
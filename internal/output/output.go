@@ -1,19 +1,72 @@
 package output
 
-import "github.com/spf13/viper"
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
 
 type Format string
 
 const (
-	Table Format = "table"
-	JSON  Format = "json"
+	Table            Format = "table"
+	JSON             Format = "json"
+	NDJSON           Format = "ndjson"
+	YAML             Format = "yaml"
+	CSV              Format = "csv"
+	TSV              Format = "tsv"
+	Markdown         Format = "markdown"
+	GoTemplate       Format = "go-template"
+	GoTemplateFile   Format = "go-template-file"
+	JSONPathTemplate Format = "jsonpath"
+	JSONPathFile     Format = "jsonpath-file"
+	DOT              Format = "dot"
+	Mermaid          Format = "mermaid"
 )
 
+// Formats lists every supported output format
+var Formats = []Format{Table, JSON, NDJSON, YAML, CSV, TSV, Markdown, GoTemplate, GoTemplateFile, JSONPathTemplate, JSONPathFile, DOT, Mermaid}
+
+// templateFormats take their template, or template file, after an "=", e.g.
+// go-template='{{.Name}}'
+var templateFormats = []Format{GoTemplate, GoTemplateFile, JSONPathTemplate, JSONPathFile}
+
+// ParseFormat validates an output format, accepting "md" for markdown. It
+// returns the template given to the template formats as well
+func ParseFormat(spec string) (Format, string, error) {
+	name, arg, hasArg := strings.Cut(spec, "=")
+
+	switch f := Format(strings.ToLower(name)); f {
+	case "":
+		return Table, "", nil
+	case "md":
+		return Markdown, "", nil
+	case Table, JSON, NDJSON, YAML, CSV, TSV, Markdown, DOT, Mermaid:
+		return f, "", nil
+	case GoTemplate, GoTemplateFile, JSONPathTemplate, JSONPathFile:
+		if !hasArg || arg == "" {
+			return "", "", fmt.Errorf("output format %s needs a template, e.g. %s=<template>", f, f)
+		}
+		return f, arg, nil
+	}
+
+	names := make([]string, 0, len(Formats))
+	for _, f := range Formats {
+		if slices.Contains(templateFormats, f) {
+			names = append(names, string(f)+"=...")
+		} else {
+			names = append(names, string(f))
+		}
+	}
+	return "", "", fmt.Errorf("unknown output format %q, expected one of: %s", spec, strings.Join(names, ", "))
+}
+
 func FormatFromConfig() Format {
-	switch viper.GetString("output") {
-	case "json":
-		return JSON
-	default:
+	f, _, err := ParseFormat(viper.GetString("output"))
+	if err != nil {
 		return Table
 	}
+	return f
 }
@@ -0,0 +1,76 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// PrintGoTemplate executes a text/template once per element of a slice, or
+// once for any other value, ending each output with a newline
+func PrintGoTemplate(text string, v any) error {
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return fmt.Errorf("invalid go-template: %w", err)
+	}
+
+	items, ok := v.([]any)
+	if !ok {
+		items = []any{v}
+	}
+
+	for _, item := range items {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, item); err != nil {
+			return fmt.Errorf("go-template: %w", err)
+		}
+		if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		if _, err := os.Stdout.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readTemplateFile reads the template of the *-file output formats
+func readTemplateFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read template file: %w", err)
+	}
+	return string(data), nil
+}
+
+// PrintTemplate prints v with one of the template output formats
+func PrintTemplate(format Format, arg string, v any) error {
+	switch format {
+	case GoTemplateFile, JSONPathFile:
+		text, err := readTemplateFile(arg)
+		if err != nil {
+			return err
+		}
+		arg = text
+	}
+
+	switch format {
+	case GoTemplate, GoTemplateFile:
+		return PrintGoTemplate(arg, v)
+	default:
+		return PrintJSONPath(arg, v)
+	}
+}
@@ -0,0 +1,46 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PrintYAML prints v as YAML using the same keys, and key order, as its JSON
+// representation
+func PrintYAML(v any) error {
+	return WriteYAML(os.Stdout, v)
+}
+
+// WriteYAML writes v to w as PrintYAML prints it
+func WriteYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	// JSON is valid YAML, decoding it into a node keeps the key order
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// blockStyle drops the flow and quoting styles inherited from JSON so the
+// output reads like hand written YAML
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
@@ -0,0 +1,65 @@
+package refresh
+
+import (
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+const (
+	// servicesConcurrencyKey is the concurrency entry limiting the services
+	// refreshed at once
+	servicesConcurrencyKey = "services"
+
+	// defaultConcurrencyKey is the concurrency entry limiting the targets of
+	// any service without its own entry
+	defaultConcurrencyKey = "default"
+)
+
+// ServiceConcurrency returns the number of services refreshed at once by
+// astat refresh, 0 meaning all of them
+func ServiceConcurrency() int {
+	services, _, _ := concurrencyLimits()
+	return services
+}
+
+// targetConcurrency returns the number of accounts/regions a service is
+// fetched from at once, 0 meaning all of them
+func targetConcurrency(service string) int {
+	_, global, perService := concurrencyLimits()
+	if n, ok := perService[service]; ok {
+		return n
+	}
+	return global
+}
+
+// concurrencyLimits reads the concurrency config key, which is either the
+// number of services refreshed at once or a map with a "services" entry, a
+// "default" entry and service names limiting the targets fetched at once
+func concurrencyLimits() (int, int, map[string]int) {
+	var services, global int
+	perService := make(map[string]int)
+
+	switch v := viper.Get("concurrency").(type) {
+	case map[string]any:
+		for key, value := range v {
+			n, err := cast.ToIntE(value)
+			if err != nil || n < 0 {
+				continue
+			}
+			switch key {
+			case servicesConcurrencyKey:
+				services = n
+			case defaultConcurrencyKey:
+				global = n
+			default:
+				perService[key] = n
+			}
+		}
+	case nil:
+	default:
+		if n, err := cast.ToIntE(v); err == nil && n > 0 {
+			services = n
+		}
+	}
+	return services, global, perService
+}
@@ -0,0 +1,228 @@
+package refresh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/sunil-saini/astat/internal/daemon"
+	"github.com/sunil-saini/astat/internal/logger"
+	"github.com/sunil-saini/astat/internal/registry"
+)
+
+// DaemonIntervalKey is how often the daemon looks for stale services
+const DaemonIntervalKey = "daemon.interval"
+
+const defaultDaemonInterval = time.Minute
+
+// DaemonInterval returns the configured daemon interval, the default one
+// when unset or invalid
+func DaemonInterval() time.Duration {
+	if d := viper.GetDuration(DaemonIntervalKey); d > 0 {
+		return d
+	}
+	return defaultDaemonInterval
+}
+
+type daemonServer struct {
+	scope    daemon.Scope
+	interval time.Duration
+	started  time.Time
+	sem      chan struct{}
+
+	mu      sync.Mutex
+	running map[string]bool
+	last    map[string]time.Time
+	wg      sync.WaitGroup
+}
+
+// RunDaemon serves the daemon requests on the listener and refreshes every
+// enabled service once stale, until the context is done or a stop request
+// comes in
+func RunDaemon(ctx context.Context, ln net.Listener) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	scope, err := daemon.CurrentScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	s := &daemonServer{
+		scope:    scope,
+		interval: DaemonInterval(),
+		started:  time.Now(),
+		running:  make(map[string]bool),
+		last:     make(map[string]time.Time),
+	}
+	if limit := ServiceConcurrency(); limit > 0 {
+		s.sem = make(chan struct{}, limit)
+	}
+
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+	go s.schedule(ctx)
+
+	logger.Info("daemon started (PID: %d), checking services every %s", os.Getpid(), s.interval)
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				break
+			}
+			logger.Error("daemon accept failed: %v", err)
+			continue
+		}
+		go s.handle(ctx, conn, cancel)
+	}
+
+	s.wg.Wait()
+	logger.Info("daemon stopped")
+	return nil
+}
+
+// schedule refreshes the stale services right away, then every interval
+func (s *daemonServer) schedule(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.refreshStale(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *daemonServer) refreshStale(ctx context.Context) {
+	services, err := SelectServices(nil, nil)
+	if err != nil {
+		logger.Error("daemon cannot select services: %v", err)
+		return
+	}
+
+	for _, svc := range services {
+		contexts, err := Contexts(ctx, svc.Name)
+		if err != nil {
+			logger.Error("%s cannot resolve cache context: %v", svc.Name, err)
+			continue
+		}
+		if _, stale := staleness(contexts, svc.Name); stale {
+			s.start(ctx, svc.Name)
+		}
+	}
+}
+
+// start refreshes a service in the background unless it already is
+func (s *daemonServer) start(ctx context.Context, service string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running[service] || ctx.Err() != nil {
+		return
+	}
+	s.running[service] = true
+
+	s.wg.Go(func() {
+		if s.sem != nil {
+			select {
+			case s.sem <- struct{}{}:
+				defer func() { <-s.sem }()
+			case <-ctx.Done():
+				s.done(service, false)
+				return
+			}
+		}
+
+		logger.Info("%s refreshing...", service)
+		refreshInternal(ctx, service, &logTracker{})
+		s.done(service, true)
+	})
+}
+
+func (s *daemonServer) done(service string, refreshed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.running, service)
+	if refreshed {
+		s.last[service] = time.Now()
+	}
+}
+
+func (s *daemonServer) status() *daemon.Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := &daemon.Status{
+		PID:         os.Getpid(),
+		Started:     s.started,
+		Scope:       s.scope,
+		Interval:    s.interval,
+		Refreshing:  make([]string, 0, len(s.running)),
+		LastRefresh: make(map[string]time.Time, len(s.last)),
+	}
+	for service := range s.running {
+		st.Refreshing = append(st.Refreshing, service)
+	}
+	slices.Sort(st.Refreshing)
+	for service, t := range s.last {
+		st.LastRefresh[service] = t
+	}
+	return st
+}
+
+// handle answers a single request per connection
+func (s *daemonServer) handle(ctx context.Context, conn net.Conn, stop context.CancelFunc) {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	var req daemon.Request
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		logger.Error("daemon cannot read request: %v", err)
+		return
+	}
+
+	resp := daemon.Response{OK: true}
+	switch req.Command {
+	case daemon.CommandRefresh:
+		if err := s.refreshRequest(ctx, req); err != nil {
+			resp = daemon.Response{Error: err.Error()}
+		}
+	case daemon.CommandStatus:
+		resp.Status = s.status()
+	case daemon.CommandStop:
+		logger.Info("daemon stop requested")
+		defer stop()
+	default:
+		resp = daemon.Response{Error: fmt.Sprintf("unknown command %q", req.Command)}
+	}
+
+	if err := json.NewEncoder(conn).Encode(resp); err != nil {
+		logger.Error("daemon cannot write response: %v", err)
+	}
+}
+
+// refreshRequest starts the refresh asked for by a CLI invocation, refusing
+// those for another scope than the daemon's
+func (s *daemonServer) refreshRequest(ctx context.Context, req daemon.Request) error {
+	if !req.Scope.Equal(s.scope) {
+		return errors.New("daemon refreshes another account, profile or regions")
+	}
+	if _, ok := registry.Lookup(req.Service); !ok {
+		return fmt.Errorf("unknown service: %s", req.Service)
+	}
+
+	logger.Info("%s refresh requested", req.Service)
+	s.start(ctx, req.Service)
+	return nil
+}
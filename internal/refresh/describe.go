@@ -0,0 +1,108 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/sunil-saini/astat/internal/aws"
+	"github.com/sunil-saini/astat/internal/cache"
+	"github.com/sunil-saini/astat/internal/logger"
+	"github.com/sunil-saini/astat/internal/model"
+	"github.com/sunil-saini/astat/internal/registry"
+)
+
+// Resource is a resource along with the context it belongs to
+type Resource struct {
+	Value   any
+	Context cache.Context
+}
+
+// Describe fetches the resources of a service matching id straight from AWS,
+// in every account and region the service is listed from. It uses the
+// service's targeted lookup when it has one, otherwise it fetches everything
+// and keeps the resources accepted by match
+func Describe(ctx context.Context, service, id string, match func(any) bool) ([]Resource, error) {
+	svc, ok := registry.Lookup(service)
+	if !ok {
+		return nil, fmt.Errorf("unknown service: %s", service)
+	}
+
+	fetch := svc.Fetch
+	if svc.Describe != nil {
+		fetch = func(ctx context.Context, cfg sdkaws.Config) (any, error) {
+			return svc.Describe(ctx, cfg, id)
+		}
+	}
+
+	found, errs, err := fetchTargets(ctx, service, fetch, match)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return found, nil
+}
+
+// Fetch fetches every resource of a service straight from AWS, in every
+// account and region the service is listed from, without caching them. It
+// fails when any of them does, rather than returning part of the resources
+func Fetch(ctx context.Context, service string) ([]Resource, error) {
+	svc, ok := registry.Lookup(service)
+	if !ok {
+		return nil, fmt.Errorf("unknown service: %s", service)
+	}
+
+	found, errs, err := fetchTargets(ctx, service, svc.Fetch, func(any) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return found, nil
+}
+
+// fetchTargets fetches a service from every target concurrently, returning
+// the resources accepted by match and the error of each failed target.
+// Partial results are kept, their errors logged as warnings
+func fetchTargets(ctx context.Context, service string, fetch func(context.Context, sdkaws.Config) (any, error), match func(any) bool) ([]Resource, []error, error) {
+	targets, err := resolveTargets(ctx, service, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		found []Resource
+		errs  []error
+	)
+	for _, t := range targets {
+		wg.Go(func() {
+			data, err := fetch(ctx, t.cfg)
+
+			mu.Lock()
+			defer mu.Unlock()
+			var partial *aws.PartialError
+			if errors.As(err, &partial) {
+				for _, w := range partial.Warnings() {
+					logger.Warn("%s: %s", t.cctx, w)
+				}
+			} else if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", t.cctx, err))
+				return
+			}
+			for _, v := range model.ToAnySlice(data) {
+				if match(v) {
+					found = append(found, Resource{Value: v, Context: t.cctx})
+				}
+			}
+		})
+	}
+	wg.Wait()
+	return found, errs, nil
+}
@@ -2,12 +2,14 @@ package refresh
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
-	"syscall"
 	"time"
 
 	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
@@ -15,6 +17,7 @@ import (
 	"github.com/spf13/viper"
 	"github.com/sunil-saini/astat/internal/aws"
 	"github.com/sunil-saini/astat/internal/cache"
+	"github.com/sunil-saini/astat/internal/daemon"
 	"github.com/sunil-saini/astat/internal/logger"
 	"github.com/sunil-saini/astat/internal/registry"
 )
@@ -35,66 +38,177 @@ func (p *ptermTracker) Error(msg string)   { p.spinner.Fail(msg) }
 
 func Refresh(ctx context.Context, resource string, fetch func(ctx context.Context, cfg sdkaws.Config) (any, error), tracker Tracker) {
 	tracker.Update(fmt.Sprintf("%s loading config...", resource))
-	cfg, err := aws.LoadConfig(ctx)
+	targets, err := resolveTargets(ctx, resource, true)
 	if err != nil {
 		tracker.Error(fmt.Sprintf("%s config failed: %v", resource, err))
 		return
 	}
 
-	dir := cache.Dir()
-	cache.EnsureDir(dir)
+	multiAccount := spansAccounts(targets)
+	fetching := fmt.Sprintf("%s fetching...", resource)
+	switch {
+	case len(targets) == 1:
+	case multiAccount:
+		fetching = fmt.Sprintf("%s fetching %d accounts/regions...", resource, len(targets))
+	default:
+		fetching = fmt.Sprintf("%s fetching %d regions...", resource, len(targets))
+	}
+	tracker.Update(fetching)
+
+	// Fetchers paging through large result sets report their running count,
+	// summed over the targets
+	var progressMu sync.Mutex
+	counts := make([]int, len(targets))
+	progress := func(i int) aws.ProgressFunc {
+		return func(count int, noun string) {
+			progressMu.Lock()
+			defer progressMu.Unlock()
+			counts[i] = count
+			total := 0
+			for _, c := range counts {
+				total += c
+			}
+			tracker.Update(fmt.Sprintf("%s %d %s", fetching, total, noun))
+		}
+	}
 
-	metaFile := cache.Path(dir, "meta")
-	var meta cache.Meta
+	var sem chan struct{}
+	if limit := targetConcurrency(resource); limit > 0 {
+		sem = make(chan struct{}, limit)
+	}
 
-	cache.LockMeta()
-	cache.Read(metaFile, &meta)
-	if meta.Services == nil {
-		meta.Services = make(map[string]cache.ServiceMeta)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var failures, partials []string
+	var busy *busyError
+	for i, t := range targets {
+		wg.Go(func() {
+			if sem != nil {
+				sem <- struct{}{}
+				defer func() { <-sem }()
+			}
+			err := refreshTarget(aws.WithProgress(ctx, progress(i)), resource, fetch, t)
+			if err == nil {
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			var b *busyError
+			if errors.As(err, &b) {
+				busy = b
+				return
+			}
+			label := t.cctx.Region
+			if multiAccount {
+				label = t.cctx.AccountID + "/" + label
+			}
+			var partial *aws.PartialError
+			if errors.As(err, &partial) {
+				partials = append(partials, fmt.Sprintf("%s: %d errors", label, len(partial.Errs)))
+				return
+			}
+			failures = append(failures, fmt.Sprintf("%s: %v", label, err))
+		})
 	}
-	sMeta := meta.Services[resource]
-	sMeta.Refreshing = true
-	sMeta.BusyPID = os.Getpid()
-	meta.Services[resource] = sMeta
-	if err := cache.Write(metaFile, &meta); err != nil {
-		logger.Error("Failed to update cache metadata: %v", err)
+	wg.Wait()
+
+	switch {
+	case len(failures) == 0 && len(partials) > 0:
+		sort.Strings(partials)
+		tracker.Error(fmt.Sprintf("%s partially refreshed (%s), see 'astat status'", resource, strings.Join(partials, "; ")))
+	case len(failures) == 0 && busy != nil:
+		tracker.Success(fmt.Sprintf("%s %v", resource, busy))
+	case len(failures) == 0:
+		tracker.Success(fmt.Sprintf("%s refreshed", resource))
+	case len(targets) == 1:
+		tracker.Error(fmt.Sprintf("%s fetch failed: %v", resource, strings.TrimPrefix(failures[0], targets[0].cctx.Region+": ")))
+	default:
+		sort.Strings(failures)
+		tracker.Error(fmt.Sprintf("%s fetch failed for %d/%d targets: %s", resource, len(failures), len(targets), strings.Join(failures, "; ")))
 	}
-	cache.UnlockMeta()
-
-	// Track whether the refresh was successful
-	success := false
-	defer func() {
-		cache.LockMeta()
-		defer cache.UnlockMeta()
+}
 
-		cache.Read(metaFile, &meta)
-		if meta.Services == nil {
-			meta.Services = make(map[string]cache.ServiceMeta)
+func spansAccounts(targets []target) bool {
+	for _, t := range targets {
+		if t.cctx.AccountID != targets[0].cctx.AccountID {
+			return true
 		}
+	}
+	return false
+}
+
+// refreshTarget fetches a service for one target and stores it in the
+// target's cache context. The service is claimed in the context metadata
+// under the cross-process lock first, so only one process refreshes it
+func refreshTarget(ctx context.Context, resource string, fetch func(ctx context.Context, cfg sdkaws.Config) (any, error), t target) error {
+	err := cache.UpdateMeta(t.cctx, func(meta *cache.Meta) error {
 		sMeta := meta.Services[resource]
-		sMeta.Refreshing = false
-		// Only update LastUpdated if the refresh was successful
-		if success {
-			sMeta.LastUpdated = time.Now()
-			meta.LastUpdated = time.Now()
+		if sMeta.IsBusy() {
+			return &busyError{pid: sMeta.BusyPID}
 		}
+		sMeta.Refreshing = true
+		sMeta.BusyPID = os.Getpid()
 		meta.Services[resource] = sMeta
-		if err := cache.Write(metaFile, &meta); err != nil {
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Track whether the refresh was successful, and why its data is partial
+	success := false
+	var partial *aws.PartialError
+	defer func() {
+		err := cache.UpdateMeta(t.cctx, func(meta *cache.Meta) error {
+			sMeta := meta.Services[resource]
+			sMeta.Refreshing = false
+			sMeta.BusyPID = 0
+			// Only update LastUpdated if the refresh was successful
+			if success {
+				sMeta.LastUpdated = time.Now()
+				meta.LastUpdated = time.Now()
+				sMeta.Warnings, sMeta.ErrorCount = nil, 0
+				if partial != nil {
+					sMeta.Warnings = partial.Warnings()
+					sMeta.ErrorCount = len(partial.Errs)
+				}
+			}
+			meta.Services[resource] = sMeta
+			return nil
+		})
+		if err != nil {
 			logger.Error("Failed to update cache metadata: %v", err)
 		}
 	}()
 
-	tracker.Update(fmt.Sprintf("%s fetching...", resource))
-	data, err := fetch(ctx, cfg)
-	if err != nil {
-		tracker.Error(fmt.Sprintf("%s fetch failed: %v", resource, err))
-		return
+	// Partial results are cached, their error still being reported
+	data, fetchErr := fetch(ctx, t.cfg)
+	if fetchErr != nil && !errors.As(fetchErr, &partial) {
+		return fetchErr
 	}
 
-	tracker.Update(fmt.Sprintf("%s saving...", resource))
-	cache.Write(cache.Path(dir, resource), data)
+	dir := cache.ContextDir(t.cctx)
+	if err := cache.Write(cache.Path(dir, resource), data); err != nil {
+		return err
+	}
 	success = true
-	tracker.Success(fmt.Sprintf("%s refreshed", resource))
+
+	if keep := viper.GetInt(cache.SnapshotsKey); keep > 0 {
+		if err := cache.TakeSnapshot(dir, resource, keep); err != nil {
+			logger.Warn("%s snapshot failed: %v", resource, err)
+		}
+	}
+	return fetchErr
+}
+
+// busyError reports a service that another process is already refreshing
+type busyError struct {
+	pid int
+}
+
+func (e *busyError) Error() string {
+	return fmt.Sprintf("already being refreshed by PID %d", e.pid)
 }
 
 func RefreshSync(ctx context.Context, resource string, fetch func(ctx context.Context, cfg sdkaws.Config) (any, error)) {
@@ -106,12 +220,11 @@ func RefreshSync(ctx context.Context, resource string, fetch func(ctx context.Co
 }
 
 func RefreshWithMulti(ctx context.Context, resource string, fetch func(ctx context.Context, cfg sdkaws.Config) (any, error), multi *pterm.MultiPrinter) {
-	var meta cache.Meta
-	cache.Read(cache.Path(cache.Dir(), "meta"), &meta)
-
-	if sMeta, ok := meta.Services[resource]; ok && sMeta.Refreshing && IsProcessAlive(sMeta.BusyPID) {
-		logger.Info("cache refresh for %s is already ongoing in another terminal (PID: %d)", resource, sMeta.BusyPID)
-		return
+	if contexts, err := Contexts(ctx, resource); err == nil {
+		if pid := busyPID(contexts, resource); pid != 0 {
+			logger.Info("cache refresh for %s is already ongoing in another terminal (PID: %d)", resource, pid)
+			return
+		}
 	}
 
 	s := createSpinner(multi.NewWriter(), resource)
@@ -136,15 +249,8 @@ func createSpinner(writer io.Writer, resource string) *pterm.SpinnerPrinter {
 }
 
 func refreshInternal(ctx context.Context, name string, tracker Tracker) {
-	var service *registry.Service
-	for _, s := range registry.Registry {
-		if s.Name == name {
-			service = &s
-			break
-		}
-	}
-
-	if service == nil {
+	service, ok := registry.Lookup(name)
+	if !ok {
 		logger.Warn("unknown service: %s", name)
 		return
 	}
@@ -186,23 +292,30 @@ func AutoRefreshIfStale(ctx context.Context, service string) {
 		return
 	}
 
-	var meta cache.Meta
-	metaPath := cache.Path(cache.Dir(), "meta")
-	err := cache.Read(metaPath, &meta)
+	contexts, err := Contexts(ctx, service)
 	if err != nil {
-		logger.Warn("cache not initialized, triggering background refresh")
-		refreshInternal(ctx, service, &silentTracker{})
+		logger.Warn("cannot resolve cache context: %v", err)
 		return
 	}
 
-	if sMeta, ok := meta.Services[service]; ok && sMeta.Refreshing && IsProcessAlive(sMeta.BusyPID) {
-		logger.Info("background refresh for %s is already ongoing (PID: %d)", service, sMeta.BusyPID)
+	if pid := busyPID(contexts, service); pid != 0 {
+		logger.Info("background refresh for %s is already ongoing (PID: %d)", service, pid)
 		return
 	}
 
-	ttl := viper.GetDuration("ttl")
-	sMeta, ok := meta.Services[service]
-	if !ok || time.Since(sMeta.LastUpdated) > ttl {
+	initialized, stale := staleness(contexts, service)
+	if stale && daemon.RequestRefresh(ctx, service) {
+		logger.Info("service %s is stale, refresh requested from the daemon", service)
+		return
+	}
+
+	if !initialized {
+		logger.Warn("cache not initialized, triggering background refresh")
+		refreshInternal(ctx, service, &silentTracker{})
+		return
+	}
+
+	if stale {
 		logger.Info("service %s is stale, auto-refreshing...", service)
 		atomic.AddInt32(&bgCount, 1)
 		bgWG.Go(func() {
@@ -212,6 +325,60 @@ func AutoRefreshIfStale(ctx context.Context, service string) {
 	}
 }
 
+// Fresh reports whether the enabled ones of the services are cached and
+// within their TTL in every context they are served from
+func Fresh(ctx context.Context, services ...string) bool {
+	for _, service := range services {
+		if !Enabled(service) {
+			continue
+		}
+		contexts, err := Contexts(ctx, service)
+		if err != nil {
+			return false
+		}
+		if initialized, stale := staleness(contexts, service); !initialized || stale {
+			return false
+		}
+	}
+	return true
+}
+
+// staleness reports whether any of the contexts has metadata, and whether
+// the service is stale in any of them
+func staleness(contexts []cache.Context, service string) (bool, bool) {
+	ttl := TTL(service)
+	initialized, stale := false, false
+	for _, c := range contexts {
+		meta, err := cache.ReadMeta(c)
+		if err != nil {
+			stale = true
+			continue
+		}
+		initialized = true
+
+		sMeta, ok := meta.Services[service]
+		if !ok || time.Since(sMeta.LastUpdated) > ttl {
+			stale = true
+		}
+	}
+	return initialized, stale
+}
+
+// busyPID returns the PID of a live process refreshing the service in any of
+// the contexts, or 0 when none is
+func busyPID(contexts []cache.Context, service string) int {
+	for _, c := range contexts {
+		meta, err := cache.ReadMeta(c)
+		if err != nil {
+			continue
+		}
+		if sMeta, ok := meta.Services[service]; ok && sMeta.IsBusy() {
+			return sMeta.BusyPID
+		}
+	}
+	return 0
+}
+
 type silentTracker struct{}
 
 func (s *silentTracker) Update(msg string) {
@@ -222,15 +389,9 @@ func (s *silentTracker) Success(msg string) {
 }
 func (s *silentTracker) Error(msg string) { logger.Error("%s", msg) }
 
-func IsProcessAlive(pid int) bool {
-	if pid <= 0 {
-		return false
-	}
-	process, err := os.FindProcess(pid)
-	if err != nil {
-		return false
-	}
+// logTracker logs the outcome of refreshes run by the daemon
+type logTracker struct{}
 
-	err = process.Signal(syscall.Signal(0))
-	return err == nil
-}
+func (l *logTracker) Update(msg string)  { _ = msg }
+func (l *logTracker) Success(msg string) { logger.Success("%s", msg) }
+func (l *logTracker) Error(msg string)   { logger.Error("%s", msg) }
@@ -0,0 +1,77 @@
+package refresh
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/sunil-saini/astat/internal/registry"
+)
+
+// EnabledServicesKey lists the names or globs of the services astat
+// refreshes and reports on, every service being enabled when it is empty
+const EnabledServicesKey = "services.enabled"
+
+// Enabled reports whether a service is enabled by the services.enabled config
+func Enabled(service string) bool {
+	patterns := viper.GetStringSlice(EnabledServicesKey)
+	return len(patterns) == 0 || matchAny(patterns, service)
+}
+
+// SelectServices returns the registered services matching any of the names
+// or globs in include, all enabled ones when include is empty, leaving out
+// those matching exclude. Globs only pick enabled services while a service
+// named exactly is picked even when disabled
+func SelectServices(include, exclude []string) ([]registry.Service, error) {
+	for _, p := range append(slices.Clone(include), exclude...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid service pattern %q: %w", p, err)
+		}
+		if !slices.ContainsFunc(registry.Registry, func(s registry.Service) bool { return matchAny([]string{p}, s.Name) }) {
+			return nil, fmt.Errorf("no service matches %q, available: %s", p, strings.Join(serviceNames(), ", "))
+		}
+	}
+
+	var selected []registry.Service
+	for _, s := range registry.Registry {
+		picked := Enabled(s.Name)
+		if len(include) > 0 {
+			picked = slices.Contains(include, s.Name) || (picked && matchAny(include, s.Name))
+		}
+		if picked && !matchAny(exclude, s.Name) {
+			selected = append(selected, s)
+		}
+	}
+	return selected, nil
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(strings.TrimSpace(p), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func serviceNames() []string {
+	names := make([]string, 0, len(registry.Registry))
+	for _, s := range registry.Registry {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+// CompleteServices completes service names for commands taking them as
+// arguments
+func CompleteServices(toComplete string) []string {
+	var names []string
+	for _, name := range serviceNames() {
+		if strings.HasPrefix(name, toComplete) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
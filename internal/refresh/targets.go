@@ -0,0 +1,115 @@
+package refresh
+
+import (
+	"context"
+	"fmt"
+
+	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/sunil-saini/astat/internal/aws"
+	"github.com/sunil-saini/astat/internal/cache"
+	"github.com/sunil-saini/astat/internal/registry"
+)
+
+// target is a region specific AWS config and the cache context its data is
+// stored in
+type target struct {
+	cfg  sdkaws.Config
+	cctx cache.Context
+}
+
+// CurrentContext resolves the cache context of the active profile and region
+func CurrentContext(ctx context.Context) (cache.Context, error) {
+	cfg, err := aws.LoadConfig(ctx)
+	if err != nil {
+		return cache.Context{}, err
+	}
+	return aws.ResolveContext(ctx, cfg)
+}
+
+// Contexts returns the cache contexts a service is served from: the global
+// context for global services, otherwise one context per configured region
+func Contexts(ctx context.Context, service string) ([]cache.Context, error) {
+	targets, err := resolveTargets(ctx, service, false)
+	if err != nil {
+		return nil, err
+	}
+
+	contexts := make([]cache.Context, 0, len(targets))
+	for _, t := range targets {
+		contexts = append(contexts, t.cctx)
+	}
+	return contexts, nil
+}
+
+// resolveTargets expands a service into the targets it is refreshed for: the
+// current account and every configured account, each in every configured
+// region. When live is set, accounts and enabled regions are confirmed with
+// AWS instead of being read from the local cache
+func resolveTargets(ctx context.Context, service string, live bool) ([]target, error) {
+	svc, ok := registry.Lookup(service)
+	if !ok {
+		return nil, fmt.Errorf("unknown service: %s", service)
+	}
+
+	cfg, err := aws.LoadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resolveContext, resolveAccounts, resolveRegions := aws.ResolveContext, aws.ResolveAccounts, aws.ResolveRegions
+	if live {
+		resolveContext, resolveAccounts, resolveRegions = aws.LookupContext, aws.LookupAccounts, aws.LookupRegions
+	}
+
+	cctx, err := resolveContext(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := resolveAccounts(ctx, cfg, cctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bases := []target{{cfg: cfg, cctx: cctx}}
+	for _, a := range accounts {
+		bases = append(bases, target{
+			cfg:  aws.AssumeRoleConfig(cfg, a),
+			cctx: cache.Context{AccountID: a.ID, Profile: a.Profile(), Region: cfg.Region},
+		})
+	}
+
+	if svc.Global {
+		targets := make([]target, 0, len(bases))
+		for _, b := range bases {
+			targets = append(targets, target{cfg: b.cfg, cctx: b.cctx.Global()})
+		}
+		return targets, nil
+	}
+
+	baseRegions, err := resolveRegions(ctx, cfg, cctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []target
+	for i, b := range bases {
+		regions := baseRegions
+		if i > 0 {
+			// Fall back to the base account's regions, the fetch then reports
+			// any access problem with the assumed role per target
+			if r, err := resolveRegions(ctx, b.cfg, b.cctx); err == nil {
+				regions = r
+			}
+		}
+
+		for _, region := range regions {
+			rCfg := b.cfg.Copy()
+			rCfg.Region = region
+			rCtx := b.cctx
+			rCtx.Region = region
+			targets = append(targets, target{cfg: rCfg, cctx: rCtx})
+		}
+	}
+	return targets, nil
+}
@@ -0,0 +1,111 @@
+package refresh
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+const (
+	// DefaultTTL applies when neither the config nor --ttl set a TTL
+	DefaultTTL = 24 * time.Hour
+
+	// TTLOverrideKey holds the value of the --ttl flag, which takes
+	// precedence over the ttl config key
+	TTLOverrideKey = "ttl-override"
+
+	defaultTTLKey = "default"
+)
+
+// TTL returns how long the cache of a service stays fresh, looking in order
+// at a service override in --ttl, a global --ttl, the service entry of the
+// ttl config map, and the global ttl config value. An empty service returns
+// the global TTL
+func TTL(service string) time.Duration {
+	global, perService := configTTLs()
+
+	overrideGlobal, overrides, err := ParseTTLSpec(viper.GetString(TTLOverrideKey))
+	if err == nil {
+		if d, ok := overrides[service]; ok && service != "" {
+			return d
+		}
+		if overrideGlobal > 0 {
+			return overrideGlobal
+		}
+	}
+
+	if d, ok := perService[service]; ok && service != "" {
+		return d
+	}
+	return global
+}
+
+// ParseTTLSpec parses a comma separated list of TTLs, where a bare duration
+// sets the global TTL and service=duration overrides a single service, e.g.
+// "1h,ec2=10m,route53-records=72h"
+func ParseTTLSpec(spec string) (time.Duration, map[string]time.Duration, error) {
+	var global time.Duration
+	perService := make(map[string]time.Duration)
+
+	for part := range strings.SplitSeq(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		service, value, found := strings.Cut(part, "=")
+		if !found {
+			service, value = "", part
+		}
+
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return 0, nil, fmt.Errorf("invalid ttl %q: %w", part, err)
+		}
+
+		if service = strings.TrimSpace(service); service == "" || service == defaultTTLKey {
+			global = d
+		} else {
+			perService[service] = d
+		}
+	}
+	return global, perService, nil
+}
+
+// configTTLs reads the ttl config key, which is either a single duration or
+// a map of service names to durations with an optional "default" entry
+func configTTLs() (time.Duration, map[string]time.Duration) {
+	global := DefaultTTL
+	perService := make(map[string]time.Duration)
+
+	switch v := viper.Get("ttl").(type) {
+	case map[string]any:
+		for service, value := range v {
+			d, err := cast.ToDurationE(value)
+			if err != nil {
+				continue
+			}
+			if service == defaultTTLKey {
+				global = d
+			} else {
+				perService[service] = d
+			}
+		}
+	case string:
+		if g, services, err := ParseTTLSpec(v); err == nil {
+			if g > 0 {
+				global = g
+			}
+			perService = services
+		}
+	case nil:
+	default:
+		if d, err := cast.ToDurationE(v); err == nil && d > 0 {
+			global = d
+		}
+	}
+	return global, perService
+}
@@ -11,85 +11,173 @@ import (
 type Service struct {
 	Name  string
 	Model any
-	Fetch func(context.Context, sdkaws.Config) (any, error)
+	// Global services are fetched once rather than per region
+	Global bool
+	// Command is the astat command listing the service, e.g. "rds instances"
+	Command string
+	// IDField is the model field identifying a resource
+	IDField string
+	Fetch   func(context.Context, sdkaws.Config) (any, error)
+	// Describe fetches the resources matching an ID or name without listing
+	// all of them, when the service's API allows it
+	Describe func(context.Context, sdkaws.Config, string) (any, error)
+	// Key identifies a resource within its context when IDField alone does
+	// not, e.g. records sharing a name
+	Key func(any) string
+}
+
+// Lookup returns the registered service with the given name
+func Lookup(name string) (Service, bool) {
+	for _, s := range Registry {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Service{}, false
 }
 
 var Registry = []Service{
 	{
-		Name:  "ec2",
-		Model: model.EC2Instance{},
+		Name:    "ec2",
+		Model:   model.EC2Instance{},
+		Command: "ec2 list",
+		IDField: "InstanceID",
 		Fetch: func(ctx context.Context, cfg sdkaws.Config) (any, error) {
 			return aws.FetchEC2Instances(ctx, cfg)
 		},
+		Describe: func(ctx context.Context, cfg sdkaws.Config, id string) (any, error) {
+			return aws.DescribeEC2Instances(ctx, cfg, id)
+		},
 	},
 	{
-		Name:  "s3",
-		Model: model.S3Bucket{},
+		Name:    "s3",
+		Model:   model.S3Bucket{},
+		Global:  true,
+		Command: "s3 list",
+		IDField: "Name",
 		Fetch: func(ctx context.Context, cfg sdkaws.Config) (any, error) {
 			return aws.FetchS3Buckets(ctx, cfg)
 		},
+		Describe: func(ctx context.Context, cfg sdkaws.Config, id string) (any, error) {
+			return aws.DescribeS3Bucket(ctx, cfg, id)
+		},
 	},
 	{
-		Name:  "lambda",
-		Model: model.LambdaFunction{},
+		Name:    "lambda",
+		Model:   model.LambdaFunction{},
+		Command: "lambda list",
+		IDField: "Name",
 		Fetch: func(ctx context.Context, cfg sdkaws.Config) (any, error) {
 			return aws.FetchLambdaFunctions(ctx, cfg)
 		},
+		Describe: func(ctx context.Context, cfg sdkaws.Config, id string) (any, error) {
+			return aws.DescribeLambdaFunction(ctx, cfg, id)
+		},
 	},
 	{
-		Name:  "cloudfront",
-		Model: model.CloudFrontDistribution{},
+		Name:    "cloudfront",
+		Model:   model.CloudFrontDistribution{},
+		Global:  true,
+		Command: "cloudfront list",
+		IDField: "ID",
 		Fetch: func(ctx context.Context, cfg sdkaws.Config) (any, error) {
 			return aws.FetchCloudFront(ctx, cfg)
 		},
 	},
 	{
-		Name:  "route53-zones",
-		Model: model.Route53HostedZone{},
+		Name:    "route53-zones",
+		Model:   model.Route53HostedZone{},
+		Global:  true,
+		Command: "route53 list",
+		IDField: "ID",
 		Fetch: func(ctx context.Context, cfg sdkaws.Config) (any, error) {
 			return aws.FetchHostedZones(ctx, cfg)
 		},
+		Describe: func(ctx context.Context, cfg sdkaws.Config, id string) (any, error) {
+			return aws.DescribeHostedZone(ctx, cfg, id)
+		},
 	},
 	{
-		Name:  "route53-records",
-		Model: model.Route53Record{},
+		Name:    "route53-records",
+		Model:   model.Route53Record{},
+		Global:  true,
+		Command: "route53 records",
+		IDField: "Name",
+		Key: func(v any) string {
+			r := v.(model.Route53Record)
+			return r.ZoneName + "|" + r.Name + "|" + r.Type + "|" + r.SetIdentifier
+		},
 		Fetch: func(ctx context.Context, cfg sdkaws.Config) (any, error) {
 			return aws.FetchAllRoute53Records(ctx, cfg)
 		},
 	},
 	{
-		Name:  "ssm",
-		Model: model.SSMParameter{},
+		Name:    "ssm",
+		Model:   model.SSMParameter{},
+		Command: "ssm list",
+		IDField: "Name",
 		Fetch: func(ctx context.Context, cfg sdkaws.Config) (any, error) {
 			return aws.FetchSSMParameters(ctx, cfg)
 		},
+		Describe: func(ctx context.Context, cfg sdkaws.Config, id string) (any, error) {
+			return aws.DescribeSSMParameter(ctx, cfg, id)
+		},
 	},
 	{
-		Name:  "elb",
-		Model: model.LoadBalancer{},
+		Name:    "elb",
+		Model:   model.LoadBalancer{},
+		Command: "elb list",
+		IDField: "Name",
 		Fetch: func(ctx context.Context, cfg sdkaws.Config) (any, error) {
 			return aws.FetchLoadBalancers(ctx, cfg)
 		},
+		Describe: func(ctx context.Context, cfg sdkaws.Config, id string) (any, error) {
+			return aws.DescribeLoadBalancer(ctx, cfg, id)
+		},
+	},
+	{
+		Name:    "elb-target-groups",
+		Model:   model.TargetGroup{},
+		Command: "elb target-groups",
+		IDField: "Name",
+		Fetch: func(ctx context.Context, cfg sdkaws.Config) (any, error) {
+			return aws.FetchTargetGroups(ctx, cfg)
+		},
 	},
 	{
-		Name:  "rds-clusters",
-		Model: model.RDSCluster{},
+		Name:    "rds-clusters",
+		Model:   model.RDSCluster{},
+		Command: "rds list",
+		IDField: "ClusterIdentifier",
 		Fetch: func(ctx context.Context, cfg sdkaws.Config) (any, error) {
 			return aws.FetchRDSClusters(ctx, cfg)
 		},
+		Describe: func(ctx context.Context, cfg sdkaws.Config, id string) (any, error) {
+			return aws.DescribeRDSCluster(ctx, cfg, id)
+		},
 	},
 	{
-		Name:  "rds-instances",
-		Model: model.RDSInstance{},
+		Name:    "rds-instances",
+		Model:   model.RDSInstance{},
+		Command: "rds instances",
+		IDField: "InstanceIdentifier",
 		Fetch: func(ctx context.Context, cfg sdkaws.Config) (any, error) {
 			return aws.FetchRDSInstances(ctx, cfg)
 		},
+		Describe: func(ctx context.Context, cfg sdkaws.Config, id string) (any, error) {
+			return aws.DescribeRDSInstance(ctx, cfg, id)
+		},
 	},
 	{
-		Name:  "sqs",
-		Model: model.SQSQueue{},
+		Name:    "sqs",
+		Model:   model.SQSQueue{},
+		Command: "sqs list",
+		IDField: "Name",
 		Fetch: func(ctx context.Context, cfg sdkaws.Config) (any, error) {
 			return aws.FetchSQSQueues(ctx, cfg)
 		},
+		Describe: func(ctx context.Context, cfg sdkaws.Config, id string) (any, error) {
+			return aws.DescribeSQSQueue(ctx, cfg, id)
+		},
 	},
 }
@@ -0,0 +1,285 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/sunil-saini/astat/internal/logger"
+	"github.com/sunil-saini/astat/internal/refresh"
+	"github.com/sunil-saini/astat/internal/tui"
+)
+
+// page is a screen of the browser, the pages opened being stacked so Esc
+// goes back to the previous one
+type page interface {
+	title() string
+	help() string
+	// body returns at most height lines, each fitted to width
+	body(b *browser, width, height int) []string
+	// key handles a key, reporting false to leave it to the browser
+	key(b *browser, ev tui.Event) bool
+}
+
+type browser struct {
+	ctx    context.Context
+	screen *tui.Screen
+	pages  []page
+	status string
+	logs   *logCapture
+	// height is the number of body lines, as of the last draw
+	height int
+
+	async chan func()
+	done  chan struct{}
+	wg    sync.WaitGroup
+
+	refreshing map[string]bool
+}
+
+// Browse runs the full-screen browser of the cached resources: pick a
+// service, filter, sort and inspect its resources, jump to related ones,
+// refresh services and trace Route53 records, until the user quits
+func Browse(ctx context.Context) error {
+	screen, err := tui.NewScreen()
+	if err != nil {
+		return fmt.Errorf("astat tui: %w", err)
+	}
+	defer screen.Close()
+
+	// Log lines would scribble over the screen, the last one is shown in the
+	// status line instead
+	logs := &logCapture{}
+	logger.SetOutput(logs)
+	defer logger.SetOutput(os.Stderr)
+
+	ctx, cancel := context.WithCancel(ctx)
+	b := &browser{
+		ctx:        ctx,
+		screen:     screen,
+		logs:       logs,
+		async:      make(chan func(), 16),
+		done:       make(chan struct{}),
+		refreshing: make(map[string]bool),
+	}
+	defer func() {
+		cancel()
+		close(b.done)
+		b.wg.Wait()
+	}()
+
+	b.push(newServicesPage(b))
+	return b.run()
+}
+
+func (b *browser) run() error {
+	b.draw()
+	for {
+		select {
+		case <-b.ctx.Done():
+			return nil
+		case ev, ok := <-b.screen.Events():
+			if !ok || b.key(ev) {
+				return nil
+			}
+		case f := <-b.async:
+			f()
+		}
+		b.draw()
+	}
+}
+
+// key dispatches a key to the current page, reporting whether to quit
+func (b *browser) key(ev tui.Event) bool {
+	if ev.Key == tui.KeyCtrlC {
+		return true
+	}
+	if ev.Key == tui.KeyResize || b.top().key(b, ev) {
+		return false
+	}
+
+	switch {
+	case ev.Key == tui.KeyEsc, ev.Key == tui.KeyRune && ev.Rune == 'q':
+		if len(b.pages) == 1 {
+			return ev.Rune == 'q'
+		}
+		b.pages = b.pages[:len(b.pages)-1]
+	}
+	return false
+}
+
+func (b *browser) draw() {
+	width, height := b.screen.Size()
+	p := b.top()
+
+	titles := make([]string, 0, len(b.pages))
+	for _, p := range b.pages {
+		titles = append(titles, p.title())
+	}
+	lines := []string{tui.Reverse(tui.Fit(" astat › "+strings.Join(titles, " › "), width))}
+
+	b.height = max(height-3, 1)
+	lines = append(lines, p.body(b, width, b.height)...)
+	for len(lines) < b.height+1 {
+		lines = append(lines, strings.Repeat(" ", width))
+	}
+
+	status := b.status
+	if line := b.logs.take(); line != "" {
+		status = line
+		b.status = line
+	}
+	lines = append(lines, tui.Yellow(tui.Fit(" "+status, width)))
+	lines = append(lines, tui.Dim(tui.Fit(" "+p.help(), width)))
+	b.screen.Draw(lines)
+}
+
+func (b *browser) top() page {
+	return b.pages[len(b.pages)-1]
+}
+
+func (b *browser) push(p page) {
+	b.pages = append(b.pages, p)
+}
+
+// post runs f on the browser's loop, from another goroutine
+func (b *browser) post(f func()) {
+	select {
+	case b.async <- f:
+	case <-b.done:
+	}
+}
+
+// goBackground runs f in the background, the browser waiting for it before
+// exiting
+func (b *browser) goBackground(f func()) {
+	b.wg.Go(f)
+}
+
+// refresh refreshes a service in the background, reloading the pages showing
+// it once done
+func (b *browser) refresh(name string) {
+	svc, err := getService(name)
+	if err != nil {
+		b.status = err.Error()
+		return
+	}
+	if b.refreshing[name] {
+		b.status = fmt.Sprintf("%s is already refreshing", name)
+		return
+	}
+
+	b.refreshing[name] = true
+	b.status = fmt.Sprintf("%s refreshing...", name)
+	b.goBackground(func() {
+		refresh.Refresh(b.ctx, name, func(ctx context.Context, cfg sdkaws.Config) (any, error) {
+			return svc.Fetch(ctx, cfg)
+		}, &browserTracker{b: b})
+
+		b.post(func() {
+			delete(b.refreshing, name)
+			for _, p := range b.pages {
+				if r, ok := p.(reloader); ok {
+					r.reload(b, name)
+				}
+			}
+		})
+	})
+}
+
+// reloader is a page showing cached data, to reload after a refresh
+type reloader interface {
+	reload(b *browser, service string)
+}
+
+// browserTracker shows the progress of a refresh in the status line
+type browserTracker struct {
+	b *browser
+}
+
+func (t *browserTracker) Update(msg string)  { t.b.post(func() { t.b.status = msg }) }
+func (t *browserTracker) Success(msg string) { t.b.post(func() { t.b.status = "✓ " + msg }) }
+func (t *browserTracker) Error(msg string)   { t.b.post(func() { t.b.status = "✗ " + msg }) }
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// logCapture keeps the last line logged
+type logCapture struct {
+	mu      sync.Mutex
+	partial strings.Builder
+	last    string
+}
+
+func (l *logCapture) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, c := range string(p) {
+		if c == '\n' {
+			l.last = ansiEscape.ReplaceAllString(l.partial.String(), "")
+			l.partial.Reset()
+			continue
+		}
+		l.partial.WriteRune(c)
+	}
+	return len(p), nil
+}
+
+// take returns the line logged since the last call, if any
+func (l *logCapture) take() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	line := l.last
+	l.last = ""
+	return line
+}
+
+// listCursor is the selected line of a scrolling list
+type listCursor struct {
+	cursor int
+	offset int
+}
+
+// handle moves the cursor with the navigation keys, reporting whether the
+// key was one of them
+func (c *listCursor) handle(ev tui.Event, n, height int) bool {
+	switch {
+	case ev.Key == tui.KeyUp, ev.Key == tui.KeyRune && ev.Rune == 'k':
+		c.cursor--
+	case ev.Key == tui.KeyDown, ev.Key == tui.KeyRune && ev.Rune == 'j':
+		c.cursor++
+	case ev.Key == tui.KeyPgUp:
+		c.cursor -= max(height-1, 1)
+	case ev.Key == tui.KeyPgDown:
+		c.cursor += max(height-1, 1)
+	case ev.Key == tui.KeyHome, ev.Key == tui.KeyRune && ev.Rune == 'g':
+		c.cursor = 0
+	case ev.Key == tui.KeyEnd, ev.Key == tui.KeyRune && ev.Rune == 'G':
+		c.cursor = n - 1
+	default:
+		return false
+	}
+	c.clamp(n)
+	return true
+}
+
+func (c *listCursor) clamp(n int) {
+	c.cursor = max(min(c.cursor, n-1), 0)
+}
+
+// window returns the range of the n lines to show in height lines, keeping
+// the cursor in view
+func (c *listCursor) window(n, height int) (int, int) {
+	c.clamp(n)
+	if c.cursor < c.offset {
+		c.offset = c.cursor
+	}
+	if c.cursor >= c.offset+height {
+		c.offset = c.cursor - height + 1
+	}
+	c.offset = max(min(c.offset, n-height), 0)
+	return c.offset, min(c.offset+height, n)
+}
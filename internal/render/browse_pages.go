@@ -0,0 +1,614 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lithammer/fuzzysearch/fuzzy"
+	"github.com/sunil-saini/astat/internal/aws"
+	"github.com/sunil-saini/astat/internal/cache"
+	"github.com/sunil-saini/astat/internal/model"
+	"github.com/sunil-saini/astat/internal/refresh"
+	"github.com/sunil-saini/astat/internal/registry"
+	"github.com/sunil-saini/astat/internal/tui"
+)
+
+// maxBrowseColumnWidth keeps a long value from taking the whole screen
+const maxBrowseColumnWidth = 40
+
+// servicesPage lists the enabled services with their cached resources
+type servicesPage struct {
+	rows []serviceRow
+	list listCursor
+}
+
+type serviceRow struct {
+	name      string
+	command   string
+	resources int
+	updated   time.Time
+}
+
+func newServicesPage(b *browser) *servicesPage {
+	p := &servicesPage{}
+	p.reload(b, "")
+	return p
+}
+
+func (p *servicesPage) title() string { return "services" }
+
+func (p *servicesPage) help() string {
+	return "↑↓ move  enter open  r refresh  q quit"
+}
+
+func (p *servicesPage) reload(b *browser, _ string) {
+	services, err := refresh.SelectServices(nil, nil)
+	if err != nil {
+		b.status = err.Error()
+		return
+	}
+
+	p.rows = p.rows[:0]
+	for _, svc := range services {
+		row := serviceRow{name: svc.Name, command: "astat " + svc.Command}
+		if contexts, err := refresh.Contexts(b.ctx, svc.Name); err == nil {
+			items, _, _ := loadItems(b.ctx, &svc, contexts)
+			row.resources = len(items)
+			for _, c := range contexts {
+				if meta, err := cache.ReadMeta(c); err == nil && meta.Services[svc.Name].LastUpdated.After(row.updated) {
+					row.updated = meta.Services[svc.Name].LastUpdated
+				}
+			}
+		}
+		p.rows = append(p.rows, row)
+	}
+}
+
+func (p *servicesPage) body(b *browser, width, height int) []string {
+	lines := []string{tui.Bold(tui.Fit(fmt.Sprintf(" %-20s %10s  %-16s %s", "SERVICE", "RESOURCES", "REFRESHED", "COMMAND"), width))}
+	from, to := p.list.window(len(p.rows), height-1)
+	for i := from; i < to; i++ {
+		r := p.rows[i]
+		refreshed := "never"
+		if !r.updated.IsZero() {
+			refreshed = time.Since(r.updated).Truncate(time.Second).String() + " ago"
+		}
+		if b.refreshing[r.name] {
+			refreshed = "refreshing..."
+		}
+
+		line := tui.Fit(fmt.Sprintf(" %-20s %10d  %-16s %s", r.name, r.resources, refreshed, r.command), width)
+		if i == p.list.cursor {
+			line = tui.Reverse(line)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (p *servicesPage) key(b *browser, ev tui.Event) bool {
+	if p.list.handle(ev, len(p.rows), b.height-1) {
+		return true
+	}
+	if len(p.rows) == 0 {
+		return false
+	}
+
+	name := p.rows[p.list.cursor].name
+	switch {
+	case ev.Key == tui.KeyEnter:
+		rp, err := newRowsPage(b, name)
+		if err != nil {
+			b.status = err.Error()
+			return true
+		}
+		b.push(rp)
+	case ev.Key == tui.KeyRune && ev.Rune == 'r':
+		b.refresh(name)
+	default:
+		return false
+	}
+	return true
+}
+
+// rowsPage lists the cached resources of a service, filtered fuzzily as the
+// user types and sorted by any column
+type rowsPage struct {
+	svc      registry.Service
+	contexts []cache.Context
+	columns  []column
+	idColumn column
+	items    []item
+	widths   []int
+
+	visible   []item
+	filter    string
+	filtering bool
+	selCol    int
+	sortCol   int
+	desc      bool
+	list      listCursor
+}
+
+func newRowsPage(b *browser, name string) (*rowsPage, error) {
+	svc, err := getService(name)
+	if err != nil {
+		return nil, err
+	}
+	contexts, err := refresh.Contexts(b.ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := tableColumns(svc.Model, contexts)
+	if err != nil {
+		return nil, err
+	}
+	idColumn, err := lookupColumn(svc.Model, svc.IDField)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &rowsPage{svc: *svc, contexts: contexts, columns: columns, idColumn: idColumn, sortCol: -1}
+	p.load(b)
+	return p, nil
+}
+
+func (p *rowsPage) title() string {
+	if len(p.visible) == len(p.items) {
+		return fmt.Sprintf("%s (%d)", p.svc.Name, len(p.items))
+	}
+	return fmt.Sprintf("%s (%d of %d)", p.svc.Name, len(p.visible), len(p.items))
+}
+
+func (p *rowsPage) help() string {
+	if p.filtering {
+		return "type to filter  enter keep  esc clear"
+	}
+	help := "↑↓ move  / filter  ←→ column  s sort  enter details  r refresh"
+	if p.svc.Name == route53RecordsService {
+		help += "  t trace"
+	}
+	return help + "  esc back"
+}
+
+func (p *rowsPage) load(b *browser) {
+	items, _, err := loadItems(b.ctx, &p.svc, p.contexts)
+	if err != nil {
+		b.status = err.Error()
+	}
+	p.items = items
+
+	p.widths = make([]int, len(p.columns))
+	for i, col := range p.columns {
+		// Room for the sort arrow
+		p.widths[i] = tui.Width(col.header) + 2
+		for _, it := range items {
+			p.widths[i] = max(p.widths[i], min(tui.Width(col.cell(it)), maxBrowseColumnWidth))
+		}
+	}
+	p.apply()
+}
+
+func (p *rowsPage) reload(b *browser, service string) {
+	if service != p.svc.Name {
+		return
+	}
+
+	selected := ""
+	if p.list.cursor < len(p.visible) {
+		selected = itemKey(&p.svc, p.idColumn, p.visible[p.list.cursor])
+	}
+	p.load(b)
+	if i := slices.IndexFunc(p.visible, func(it item) bool { return itemKey(&p.svc, p.idColumn, it) == selected }); i >= 0 {
+		p.list.cursor = i
+	}
+}
+
+// apply sorts the items and keeps those matching the filter, best matches
+// first unless sorted by a column
+func (p *rowsPage) apply() {
+	items := slices.Clone(p.items)
+	if p.sortCol >= 0 {
+		sortItems(items, p.columns[p.sortCol], p.desc)
+	}
+
+	if p.filter != "" {
+		type match struct {
+			it   item
+			rank int
+		}
+		var matches []match
+		for _, it := range items {
+			best := -1
+			for _, col := range p.columns {
+				if r := fuzzy.RankMatchFold(p.filter, col.cell(it)); r >= 0 && (best < 0 || r < best) {
+					best = r
+				}
+			}
+			if best >= 0 {
+				matches = append(matches, match{it: it, rank: best})
+			}
+		}
+		if p.sortCol < 0 {
+			slices.SortStableFunc(matches, func(a, b match) int { return a.rank - b.rank })
+		}
+
+		items = items[:0]
+		for _, m := range matches {
+			items = append(items, m.it)
+		}
+	}
+
+	p.visible = items
+	p.list.clamp(len(items))
+}
+
+func (p *rowsPage) body(b *browser, width, height int) []string {
+	var lines []string
+	if p.filtering || p.filter != "" {
+		prompt := " / " + p.filter
+		if p.filtering {
+			prompt += "█"
+		}
+		lines = append(lines, tui.Cyan(tui.Fit(prompt, width)))
+	}
+
+	if len(p.items) == 0 {
+		return append(lines, tui.Fit(fmt.Sprintf(" No cached %s resources, press r to refresh", p.svc.Name), width))
+	}
+
+	headers := make([]string, len(p.columns))
+	for i, col := range p.columns {
+		headers[i] = col.header
+		if i == p.sortCol {
+			headers[i] += map[bool]string{false: " ▲", true: " ▼"}[p.desc]
+		}
+	}
+	cells := layoutCells(headers, p.widths, width)
+	for i := range cells {
+		cells[i] = tui.Bold(cells[i])
+		if i == p.selCol {
+			cells[i] = tui.Underline(cells[i])
+		}
+	}
+	lines = append(lines, " "+strings.Join(cells, " "))
+
+	from, to := p.list.window(len(p.visible), height-len(lines))
+	for i := from; i < to; i++ {
+		line := tui.Fit(" "+strings.Join(layoutCells(rowCells(p.visible[i], p.columns), p.widths, width), " "), width)
+		if i == p.list.cursor {
+			line = tui.Reverse(line)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (p *rowsPage) key(b *browser, ev tui.Event) bool {
+	if p.filtering {
+		switch ev.Key {
+		case tui.KeyRune:
+			p.filter += string(ev.Rune)
+		case tui.KeyBackspace:
+			if r := []rune(p.filter); len(r) > 0 {
+				p.filter = string(r[:len(r)-1])
+			}
+		case tui.KeyEnter:
+			p.filtering = false
+			return true
+		case tui.KeyEsc:
+			p.filter, p.filtering = "", false
+		default:
+			return p.list.handle(ev, len(p.visible), b.height-2)
+		}
+		p.list.cursor = 0
+		p.apply()
+		return true
+	}
+
+	if p.list.handle(ev, len(p.visible), b.height-2) {
+		return true
+	}
+
+	switch {
+	case ev.Key == tui.KeyRune && ev.Rune == '/':
+		p.filtering = true
+	case ev.Key == tui.KeyEsc && p.filter != "":
+		p.filter = ""
+		p.apply()
+	case ev.Key == tui.KeyLeft, ev.Key == tui.KeyRune && ev.Rune == 'h':
+		p.selCol = max(p.selCol-1, 0)
+	case ev.Key == tui.KeyRight, ev.Key == tui.KeyRune && ev.Rune == 'l':
+		p.selCol = min(p.selCol+1, len(p.columns)-1)
+	case ev.Key == tui.KeyRune && ev.Rune == 's':
+		if p.sortCol == p.selCol {
+			p.desc = !p.desc
+		} else {
+			p.sortCol, p.desc = p.selCol, false
+		}
+		p.apply()
+	case ev.Key == tui.KeyRune && ev.Rune == 'r':
+		b.refresh(p.svc.Name)
+	case ev.Key == tui.KeyEnter && len(p.visible) > 0:
+		b.push(newDetailPage(b, p.svc, p.visible[p.list.cursor]))
+	case ev.Key == tui.KeyRune && ev.Rune == 't' && len(p.visible) > 0:
+		b.traceResource(p.visible[p.list.cursor].value)
+	default:
+		return false
+	}
+	return true
+}
+
+// layoutCells fits the cells to the column widths, leaving out or cutting
+// the columns beyond the screen width
+func layoutCells(cells []string, widths []int, width int) []string {
+	out := make([]string, 0, len(cells))
+	used := 1
+	for i, cell := range cells {
+		w := min(widths[i], width-used)
+		if w <= 0 {
+			break
+		}
+		out = append(out, tui.Fit(cell, w))
+		used += w + 1
+	}
+	return out
+}
+
+// detailPage shows every field of a resource and the cached resources
+// related to it, which can be jumped to
+type detailPage struct {
+	desc   Description
+	fields [][]string
+	sel    int
+	offset int
+}
+
+func newDetailPage(b *browser, svc registry.Service, it item) *detailPage {
+	descriptions := []Description{describeItem(svc, it, sourceCache)}
+	addRelated(b.ctx, descriptions)
+	return &detailPage{desc: descriptions[0], fields: detailRows(descriptions[0], true)}
+}
+
+func (p *detailPage) title() string { return p.desc.ID }
+
+func (p *detailPage) help() string {
+	help := "↑↓ scroll"
+	if len(p.desc.Related) > 0 {
+		help = "↑↓ related  enter jump"
+	}
+	if p.desc.Service == route53RecordsService {
+		help += "  t trace"
+	}
+	return help + "  esc back"
+}
+
+// lines renders the fields and related resources, returning the index of
+// the line of the first related resource
+func (p *detailPage) lines(width int) ([]string, int) {
+	nameWidth := 0
+	for _, f := range p.fields {
+		nameWidth = min(max(nameWidth, tui.Width(f[0])), 28)
+	}
+
+	var lines []string
+	for _, f := range p.fields {
+		for i, value := range strings.Split(f[1], "\n") {
+			name := ""
+			if i == 0 {
+				name = f[0]
+			}
+			lines = append(lines, tui.Fit(" "+tui.Fit(name, nameWidth)+"  "+value, width))
+		}
+	}
+
+	if len(p.desc.Related) == 0 {
+		return lines, len(lines)
+	}
+	lines = append(lines, "", tui.Bold(tui.Fit(" Related resources", width)))
+	start := len(lines)
+	for i, r := range p.desc.Related {
+		line := tui.Fit(fmt.Sprintf(" %-20s %-40s %s = %s", r.Service, r.ID, r.Field, r.Value), width)
+		if i == p.sel {
+			line = tui.Reverse(line)
+		}
+		lines = append(lines, line)
+	}
+	return lines, start
+}
+
+func (p *detailPage) body(b *browser, width, height int) []string {
+	lines, start := p.lines(width)
+	if len(p.desc.Related) > 0 {
+		// Keep the selected related resource in view
+		line := start + p.sel
+		if line < p.offset {
+			p.offset = line
+		}
+		if line >= p.offset+height {
+			p.offset = line - height + 1
+		}
+	}
+	p.offset = max(min(p.offset, len(lines)-height), 0)
+	return lines[p.offset:min(p.offset+height, len(lines))]
+}
+
+func (p *detailPage) key(b *browser, ev tui.Event) bool {
+	related := len(p.desc.Related) > 0
+	switch {
+	case ev.Key == tui.KeyUp, ev.Key == tui.KeyRune && ev.Rune == 'k':
+		if related {
+			p.sel = max(p.sel-1, 0)
+		} else {
+			p.offset--
+		}
+	case ev.Key == tui.KeyDown, ev.Key == tui.KeyRune && ev.Rune == 'j':
+		if related {
+			p.sel = min(p.sel+1, len(p.desc.Related)-1)
+		} else {
+			p.offset++
+		}
+	case ev.Key == tui.KeyPgUp:
+		p.offset -= b.height - 1
+		if related {
+			p.sel = 0
+		}
+	case ev.Key == tui.KeyPgDown:
+		p.offset += b.height - 1
+	case ev.Key == tui.KeyEnter && related:
+		b.jump(p.desc.Related[p.sel])
+	case ev.Key == tui.KeyRune && ev.Rune == 't':
+		b.traceResource(p.desc.Resource)
+	default:
+		return false
+	}
+	p.offset = max(p.offset, 0)
+	return true
+}
+
+// jump opens the detail page of a related resource, over the list of its
+// service so going back lists its siblings
+func (b *browser) jump(r SearchResult) {
+	rp, err := newRowsPage(b, r.Service)
+	if err != nil {
+		b.status = err.Error()
+		return
+	}
+	i := slices.IndexFunc(rp.visible, func(it item) bool {
+		return rp.idColumn.cell(it) == r.ID && it.cctx.AccountID == r.Account && it.cctx.Region == r.Region
+	})
+	if i < 0 {
+		b.status = fmt.Sprintf("%s %s is no longer cached", r.Service, r.ID)
+		return
+	}
+
+	rp.list.cursor = i
+	b.push(rp)
+	b.push(newDetailPage(b, rp.svc, rp.visible[i]))
+}
+
+// route53RecordsService is the service whose records can be traced
+const route53RecordsService = "route53-records"
+
+// traceResource traces the domain of a Route53 record
+func (b *browser) traceResource(v any) {
+	record, ok := v.(model.Route53Record)
+	if !ok {
+		b.status = "only Route53 records can be traced"
+		return
+	}
+	b.openTrace(record.Name)
+}
+
+// tracePage shows the trace of a domain through AWS
+type tracePage struct {
+	domain  string
+	lines   []string
+	styles  []func(string) string
+	loading bool
+	offset  int
+}
+
+func (b *browser) openTrace(domain string) {
+	domain = strings.TrimSuffix(domain, ".")
+	p := &tracePage{domain: domain, loading: true}
+	b.push(p)
+
+	b.goBackground(func() {
+		lines, styles := traceLines(b.ctx, domain)
+		b.post(func() {
+			p.lines, p.styles, p.loading = lines, styles, false
+		})
+	})
+}
+
+func (p *tracePage) title() string { return "trace " + p.domain }
+
+func (p *tracePage) help() string { return "↑↓ scroll  esc back" }
+
+func (p *tracePage) body(b *browser, width, height int) []string {
+	if p.loading {
+		return []string{tui.Fit(fmt.Sprintf(" Tracing %s...", p.domain), width)}
+	}
+
+	p.offset = max(min(p.offset, len(p.lines)-height), 0)
+	lines := make([]string, 0, height)
+	for i := p.offset; i < len(p.lines) && len(lines) < height; i++ {
+		lines = append(lines, p.styles[i](tui.Fit(" "+p.lines[i], width)))
+	}
+	return lines
+}
+
+func (p *tracePage) key(b *browser, ev tui.Event) bool {
+	switch {
+	case ev.Key == tui.KeyUp, ev.Key == tui.KeyRune && ev.Rune == 'k':
+		p.offset--
+	case ev.Key == tui.KeyDown, ev.Key == tui.KeyRune && ev.Rune == 'j':
+		p.offset++
+	case ev.Key == tui.KeyPgUp:
+		p.offset -= b.height - 1
+	case ev.Key == tui.KeyPgDown:
+		p.offset += b.height - 1
+	default:
+		return false
+	}
+	p.offset = max(p.offset, 0)
+	return true
+}
+
+// traceLines traces a domain and renders it as a tree, with the style of
+// each line
+func traceLines(ctx context.Context, domain string) ([]string, []func(string) string) {
+	cfg, err := aws.LoadConfig(ctx)
+	if err != nil {
+		return []string{"✗ " + err.Error()}, []func(string) string{tui.Red}
+	}
+	result, err := aws.TraceDomain(ctx, cfg, domain, aws.TraceOptions{Cached: refresh.Fresh(ctx, aws.TraceServices...), Enabled: refresh.Enabled})
+	if err != nil {
+		return []string{"✗ " + err.Error()}, []func(string) string{tui.Red}
+	}
+
+	lines := []string{domain}
+	styles := []func(string) string{tui.Bold}
+	if len(result.Hops) == 0 {
+		lines = append(lines, "No path found for domain")
+		styles = append(styles, tui.Yellow)
+	}
+
+	var walk func(nodes []model.TraceNode, prefix string)
+	walk = func(nodes []model.TraceNode, prefix string) {
+		for i, n := range nodes {
+			branch, indent := "├─ ", "│  "
+			if i == len(nodes)-1 {
+				branch, indent = "└─ ", "   "
+			}
+
+			text := fmt.Sprintf("%s%s[%s] %s", prefix, branch, n.Type, n.Name)
+			if n.Value != "" {
+				text += " -> " + n.Value
+			}
+			style := func(s string) string { return s }
+			switch n.Status {
+			case model.StatusHealthy:
+				style = tui.Green
+			case model.StatusUnhealthy:
+				style = tui.Red
+			case model.StatusUnavailable:
+				style = tui.Dim
+			}
+			lines = append(lines, text)
+			styles = append(styles, style)
+			walk(n.Children, prefix+indent)
+		}
+	}
+	walk(result.Hops, "")
+
+	for _, w := range result.Warnings {
+		lines = append(lines, "⚠ "+w)
+		styles = append(styles, tui.Yellow)
+	}
+	return lines, styles
+}
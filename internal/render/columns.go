@@ -0,0 +1,320 @@
+package render
+
+import (
+	"cmp"
+	"fmt"
+	"net/netip"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/sunil-saini/astat/internal/cache"
+)
+
+// column is a table column and how to read its value from an item
+type column struct {
+	header string
+	value  func(it item) any
+}
+
+func (c column) cell(it item) string {
+	return formatCell(c.value(it))
+}
+
+var (
+	accountColumn = column{
+		header: "Account",
+		value:  func(it item) any { return it.cctx.AccountID },
+	}
+	regionColumn = column{
+		header: "Region",
+		value:  func(it item) any { return it.cctx.Region },
+	}
+)
+
+// contextColumns returns the extra columns needed to tell items of different
+// contexts apart: Account and Region when listing several of them
+func contextColumns(contexts []cache.Context) []column {
+	accounts := make(map[string]bool)
+	regions := make(map[string]bool)
+	for _, c := range contexts {
+		accounts[c.AccountID] = true
+		regions[c.Region] = true
+	}
+
+	var columns []column
+	if len(accounts) > 1 {
+		columns = append(columns, accountColumn)
+	}
+	if len(regions) > 1 {
+		columns = append(columns, regionColumn)
+	}
+	return columns
+}
+
+// tableColumns returns the columns to print: exactly the fields given with
+// --columns, otherwise the context columns followed by the header tagged
+// fields, or by every field with --wide, and the tag-columns tag keys
+func tableColumns(m any, contexts []cache.Context) ([]column, error) {
+	if names := viper.GetStringSlice("columns"); len(names) > 0 {
+		columns := make([]column, 0, len(names))
+		for _, name := range names {
+			col, err := lookupColumn(m, name)
+			if err != nil {
+				return nil, err
+			}
+			columns = append(columns, col)
+		}
+		return columns, nil
+	}
+
+	columns := append(contextColumns(contexts), modelColumns(m, viper.GetBool("wide"))...)
+	return append(columns, promotedTagColumns(m, columns)...), nil
+}
+
+// modelColumns returns a column per header tagged field of a model, or per
+// exported field when all is set
+func modelColumns(m any, all bool) []column {
+	var columns []column
+	typ := reflect.TypeOf(m)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		header := field.Tag.Get("header")
+		if header == "" {
+			if !all {
+				continue
+			}
+			header = field.Name
+		}
+		columns = append(columns, fieldColumn(i, header))
+	}
+	return columns
+}
+
+// lookupColumn resolves a field name, matched case insensitively and ignoring
+// spaces against the model's header tags, its struct field names, Account
+// and Region, or a tag:<key> tag
+func lookupColumn(m any, name string) (column, error) {
+	if tag, ok := tagKey(name); ok {
+		return tagColumn(m, tag)
+	}
+	key := normalizeField(name)
+
+	typ := reflect.TypeOf(m)
+	for _, byHeader := range []bool{true, false} {
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			candidate := field.Name
+			if byHeader {
+				candidate = field.Tag.Get("header")
+			}
+			if !field.IsExported() || candidate == "" || normalizeField(candidate) != key {
+				continue
+			}
+
+			header := field.Tag.Get("header")
+			if header == "" {
+				header = field.Name
+			}
+			return fieldColumn(i, header), nil
+		}
+	}
+
+	for _, col := range []column{accountColumn, regionColumn} {
+		if normalizeField(col.header) == key {
+			return col, nil
+		}
+	}
+
+	return column{}, fmt.Errorf("unknown field %q, available: %s", name, strings.Join(fieldNames(m), ", "))
+}
+
+func fieldColumn(idx int, header string) column {
+	return column{
+		header: header,
+		value:  func(it item) any { return reflect.ValueOf(it.value).Field(idx).Interface() },
+	}
+}
+
+// fieldNames lists the names a field can be referred to with, preferring the
+// header over the struct field name
+func fieldNames(m any) []string {
+	var names []string
+	typ := reflect.TypeOf(m)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if header := field.Tag.Get("header"); header != "" {
+			names = append(names, header)
+		} else {
+			names = append(names, field.Name)
+		}
+	}
+	for _, col := range []column{accountColumn, regionColumn} {
+		if !slices.Contains(names, col.header) {
+			names = append(names, col.header)
+		}
+	}
+	return names
+}
+
+// searchColumns returns the columns a search term is matched against: the
+// listed columns first, so the match shown is a visible one, then every
+// field of the model, listed or not
+func searchColumns(m any, columns []column) []column {
+	return append(slices.Clone(columns), modelColumns(m, true)...)
+}
+
+// matchColumn returns the first column whose cell contains the lower case
+// search term, along with the cell
+func matchColumn(it item, columns []column, searchTerm string) (column, string, bool) {
+	for _, col := range columns {
+		cell := col.cell(it)
+		if strings.Contains(strings.ToLower(cell), searchTerm) {
+			return col, cell, true
+		}
+	}
+	return column{}, "", false
+}
+
+func normalizeField(name string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "", "_", "").Replace(name))
+}
+
+// sortBy parses a <field>[:asc|:desc] sort spec. It returns a nil column when
+// the spec is empty
+func sortBy(m any, spec string) (*column, bool, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, false, nil
+	}
+
+	name, order := spec, ""
+	if i := strings.LastIndex(spec, ":"); i >= 0 {
+		name, order = spec[:i], spec[i+1:]
+		// the colon of a bare tag:<key> is not an order separator
+		if _, isTag := tagKey(spec); isTag {
+			if _, ok := tagKey(name); !ok {
+				name, order = spec, ""
+			}
+		}
+	}
+	var desc bool
+	switch strings.ToLower(strings.TrimSpace(order)) {
+	case "", "asc":
+	case "desc":
+		desc = true
+	default:
+		return nil, false, fmt.Errorf("invalid sort order %q, expected asc or desc", order)
+	}
+
+	col, err := lookupColumn(m, name)
+	if err != nil {
+		return nil, false, err
+	}
+	return &col, desc, nil
+}
+
+// sortItems sorts items by the value of a column, keeping the cache order of
+// equal items
+func sortItems(items []item, col column, desc bool) {
+	slices.SortStableFunc(items, func(a, b item) int {
+		c := compareValues(col.value(a), col.value(b))
+		if desc {
+			return -c
+		}
+		return c
+	})
+}
+
+// compareValues orders two values of the same field by their type: numbers
+// numerically, and strings as numbers, IP addresses or timestamps when both
+// parse as such
+func compareValues(a, b any) int {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Kind() == vb.Kind() {
+		switch va.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return cmp.Compare(va.Int(), vb.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return cmp.Compare(va.Uint(), vb.Uint())
+		case reflect.Float32, reflect.Float64:
+			return cmp.Compare(va.Float(), vb.Float())
+		case reflect.Bool:
+			return cmp.Compare(boolRank(va.Bool()), boolRank(vb.Bool()))
+		}
+	}
+	return compareStrings(formatCell(a), formatCell(b))
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// timeLayouts are the timestamp formats found in cached models
+var timeLayouts = []string{
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+	"2006-01-02T15:04:05.000-0700",
+}
+
+func compareStrings(a, b string) int {
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		return cmp.Compare(x, y)
+	}
+
+	if ipA, err := netip.ParseAddr(a); err == nil {
+		if ipB, err := netip.ParseAddr(b); err == nil {
+			return ipA.Compare(ipB)
+		}
+	}
+
+	for _, layout := range timeLayouts {
+		ta, errA := time.Parse(layout, a)
+		tb, errB := time.Parse(layout, b)
+		if errA == nil && errB == nil {
+			return ta.Compare(tb)
+		}
+	}
+
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// formatCell renders a field value for a table cell, listing string slices
+// and maps as comma separated values
+func formatCell(v any) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case []string:
+		return strings.Join(val, ", ")
+	case map[string]string:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		pairs := make([]string, 0, len(keys))
+		for _, k := range keys {
+			pairs = append(pairs, k+"="+val[k])
+		}
+		return strings.Join(pairs, ", ")
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,57 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/sunil-saini/astat/internal/cache"
+	"github.com/sunil-saini/astat/internal/model"
+)
+
+func TestSearchColumnsUnlistedFields(t *testing.T) {
+	svc, err := getService("ec2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	it := item{
+		value: model.EC2Instance{
+			InstanceID:     "i-0123456789abcdef0",
+			Name:           "web-1",
+			State:          "running",
+			VPCID:          "vpc-0a1b2c3d",
+			SubnetID:       "subnet-0a1b2c3d",
+			SecurityGroups: []string{"sg-0aaa1111", "sg-0bbb2222"},
+			ImageID:        "ami-0c0c0c0c",
+		},
+		cctx: cache.Context{AccountID: "123456789012", Profile: "default", Region: "us-east-1"},
+	}
+	listed := modelColumns(svc.Model, false)
+	columns := searchColumns(svc.Model, listed)
+
+	tests := []struct {
+		term   string
+		header string
+	}{
+		{term: "web-1", header: "Name"},
+		{term: "vpc-0a1b", header: "VPCID"},
+		{term: "sg-0bbb", header: "SecurityGroups"},
+		{term: "ami-0c0c", header: "ImageID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.term, func(t *testing.T) {
+			if _, _, ok := matchColumn(it, listed, tt.term); ok && tt.header != "Name" {
+				t.Fatalf("%q matched a listed column, the test needs an unlisted field", tt.term)
+			}
+			col, _, ok := matchColumn(it, columns, tt.term)
+			if !ok {
+				t.Fatalf("%q did not match", tt.term)
+			}
+			if col.header != tt.header {
+				t.Errorf("%q matched %s, want %s", tt.term, col.header, tt.header)
+			}
+		})
+	}
+
+	if _, _, ok := matchColumn(it, columns, "sg-0ccc"); ok {
+		t.Error("sg-0ccc matched an instance without that security group")
+	}
+}
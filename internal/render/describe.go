@@ -0,0 +1,466 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/sunil-saini/astat/internal/logger"
+	"github.com/sunil-saini/astat/internal/model"
+	"github.com/sunil-saini/astat/internal/output"
+	"github.com/sunil-saini/astat/internal/refresh"
+	"github.com/sunil-saini/astat/internal/registry"
+)
+
+// Description is everything known about a single resource
+type Description struct {
+	Service  string         `json:"service"`
+	Account  string         `json:"account"`
+	Region   string         `json:"region"`
+	ID       string         `json:"id"`
+	Source   string         `json:"source"`
+	Resource any            `json:"resource"`
+	Related  []SearchResult `json:"related"`
+}
+
+const (
+	sourceCache = "cache"
+	sourceAWS   = "aws"
+)
+
+// referenceFields hold the values other resources point at, e.g. the DNS
+// name of a load balancer in a Route53 record or a CloudFront origin
+var referenceFields = []string{
+	"InstanceID", "PrivateIP", "PublicIP", "DNSName", "ARN", "Domain", "Aliases",
+	"Origins", "DefaultOrigin", "Endpoint", "ReaderEndpoint", "Value",
+	"LoadBalancerARNs",
+}
+
+// Describe prints every field of the resources of the given services whose
+// ID, name or ARN is id, along with the cached resources related to them. It
+// looks in the cache first, and asks AWS directly when the cache has no match
+func Describe(cmd *cobra.Command, id string, services ...string) error {
+	ctx := cmd.Context()
+
+	format, _, err := output.ParseFormat(viper.GetString("output"))
+	if err != nil {
+		return err
+	}
+
+	var descriptions []Description
+	for _, name := range services {
+		svc, err := getService(name)
+		if err != nil {
+			return err
+		}
+
+		contexts, err := refresh.Contexts(ctx, name)
+		if err != nil {
+			return err
+		}
+		items, _, err := loadItems(ctx, svc, contexts)
+		if err != nil {
+			return err
+		}
+
+		for _, it := range items {
+			if matchesID(*svc, it.value, id) {
+				descriptions = append(descriptions, describeItem(*svc, it, sourceCache))
+			}
+		}
+	}
+
+	if len(descriptions) == 0 {
+		logger.Info("%s not found in cache, looking it up in AWS...", id)
+		for _, name := range services {
+			svc, err := getService(name)
+			if err != nil {
+				return err
+			}
+
+			found, err := refresh.Describe(ctx, name, id, func(v any) bool { return matchesID(*svc, v, id) })
+			if err != nil {
+				logger.Warn("%s lookup failed: %v", name, err)
+				continue
+			}
+			for _, r := range found {
+				descriptions = append(descriptions, describeItem(*svc, item{value: r.Value, cctx: r.Context}, sourceAWS))
+			}
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(descriptions) == 0 {
+		return fmt.Errorf("no %s resource found with ID or name %q", strings.Join(services, " or "), id)
+	}
+
+	addRelated(ctx, descriptions)
+
+	if format == output.Table {
+		for _, d := range descriptions {
+			printDescription(d)
+		}
+		return nil
+	}
+
+	var data any = descriptions[0]
+	if len(descriptions) > 1 {
+		list := make([]any, 0, len(descriptions))
+		for _, d := range descriptions {
+			list = append(list, d)
+		}
+		data = list
+	}
+
+	var rows [][]string
+	for _, d := range descriptions {
+		for _, row := range detailRows(d, true) {
+			rows = append(rows, append([]string{d.Service, d.ID}, row...))
+		}
+	}
+
+	return Print(TableData{
+		Headers: []string{"Service", "ID", "Field", "Value"},
+		Rows:    rows,
+		JSON:    data,
+	})
+}
+
+// CompleteIDs completes the IDs of the cached resources of the given services
+func CompleteIDs(services ...string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		var ids []string
+		for _, name := range services {
+			svc, err := getService(name)
+			if err != nil {
+				continue
+			}
+			contexts, err := refresh.Contexts(ctx, name)
+			if err != nil {
+				continue
+			}
+			items, _, err := loadItems(ctx, svc, contexts)
+			if err != nil {
+				continue
+			}
+			idColumn, err := lookupColumn(svc.Model, svc.IDField)
+			if err != nil {
+				continue
+			}
+
+			for _, it := range items {
+				id := idColumn.cell(it)
+				if strings.HasPrefix(strings.ToLower(id), strings.ToLower(toComplete)) && !slices.Contains(ids, id) {
+					ids = append(ids, id)
+				}
+			}
+		}
+		return ids, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func describeItem(svc registry.Service, it item, source string) Description {
+	id := ""
+	if idColumn, err := lookupColumn(svc.Model, svc.IDField); err == nil {
+		id = idColumn.cell(it)
+	}
+
+	return Description{
+		Service:  svc.Name,
+		Account:  it.cctx.AccountID,
+		Region:   it.cctx.Region,
+		ID:       id,
+		Source:   source,
+		Resource: it.value,
+		Related:  []SearchResult{},
+	}
+}
+
+// matchesID reports whether the ID, name or ARN of a resource is id
+func matchesID(svc registry.Service, v any, id string) bool {
+	want := normalizeID(id)
+	val := reflect.ValueOf(v)
+	for _, name := range []string{svc.IDField, "Name", "ARN"} {
+		f := val.FieldByName(name)
+		if f.IsValid() && f.Kind() == reflect.String && f.String() != "" && normalizeID(f.String()) == want {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeID(id string) string {
+	return strings.TrimPrefix(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(id)), "."), "/hostedzone/")
+}
+
+// addRelated fills in the cached resources of every service that refer to,
+// or are referred to by, each described resource
+func addRelated(ctx context.Context, descriptions []Description) {
+	all := make([][]item, len(registry.Registry))
+	var wg sync.WaitGroup
+	for i, svc := range registry.Registry {
+		wg.Go(func() {
+			contexts, err := refresh.Contexts(ctx, svc.Name)
+			if err != nil {
+				return
+			}
+			items, _, err := loadItems(ctx, &svc, contexts)
+			if err != nil {
+				return
+			}
+			all[i] = items
+		})
+	}
+	wg.Wait()
+
+	for i := range descriptions {
+		d := &descriptions[i]
+		svc, ok := registry.Lookup(d.Service)
+		if !ok {
+			continue
+		}
+		refs := references(svc, d.Resource)
+		if len(refs) == 0 {
+			continue
+		}
+
+		for j, other := range registry.Registry {
+			idColumn, err := lookupColumn(other.Model, other.IDField)
+			if err != nil {
+				continue
+			}
+
+			for _, it := range all[j] {
+				id := idColumn.cell(it)
+				if other.Name == d.Service && id == d.ID && it.cctx.AccountID == d.Account && it.cctx.Region == d.Region {
+					continue
+				}
+
+				otherRefs := references(other, it.value)
+				tokens := make([]string, 0, len(otherRefs))
+				for tok := range otherRefs {
+					tokens = append(tokens, tok)
+				}
+				sort.Strings(tokens)
+
+				for _, tok := range tokens {
+					if _, ok := refs[tok]; !ok {
+						continue
+					}
+					d.Related = append(d.Related, SearchResult{
+						Service:  other.Name,
+						Account:  it.cctx.AccountID,
+						Region:   it.cctx.Region,
+						ID:       id,
+						Field:    otherRefs[tok],
+						Value:    tok,
+						Command:  drillInCommand(other, id),
+						Resource: it.value,
+					})
+					break
+				}
+			}
+		}
+	}
+}
+
+// references returns the normalized values of the reference fields, and the
+// ID field, of a resource mapped to the header of the field holding them
+func references(svc registry.Service, v any) map[string]string {
+	refs := make(map[string]string)
+
+	val := reflect.ValueOf(v)
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Name != svc.IDField && !slices.Contains(referenceFields, field.Name) {
+			continue
+		}
+
+		header := field.Tag.Get("header")
+		if header == "" {
+			header = field.Name
+		}
+		for _, tok := range referenceTokens(val.Field(i).Interface()) {
+			if _, ok := refs[tok]; !ok {
+				refs[tok] = header
+			}
+		}
+	}
+
+	// CloudFront origins and DNS records point at buckets by their endpoints
+	if b, ok := v.(model.S3Bucket); ok {
+		for _, host := range []string{
+			b.Name + ".s3.amazonaws.com",
+			b.Name + ".s3." + b.Region + ".amazonaws.com",
+			b.Name + ".s3-website-" + b.Region + ".amazonaws.com",
+			b.Name + ".s3-website." + b.Region + ".amazonaws.com",
+		} {
+			refs[strings.ToLower(host)] = "Name"
+		}
+	}
+	return refs
+}
+
+// referenceTokens splits a field value into the normalized host names, IPs
+// and identifiers it holds
+func referenceTokens(v any) []string {
+	var values []string
+	switch t := v.(type) {
+	case string:
+		values = strings.FieldsFunc(t, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+	case []string:
+		values = t
+	case map[string]string:
+		for _, value := range t {
+			values = append(values, value)
+		}
+	}
+
+	var tokens []string
+	for _, s := range values {
+		s = strings.TrimPrefix(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "."), "dualstack.")
+		if len(s) >= 3 {
+			tokens = append(tokens, s)
+		}
+	}
+	return tokens
+}
+
+func printDescription(d Description) {
+	title := fmt.Sprintf("%s %s", d.Service, d.ID)
+	if d.Source == sourceAWS {
+		title += " (live from AWS, not cached)"
+	}
+	pterm.DefaultSection.Println(title)
+
+	t := output.NewTable([]string{"Field", "Value"})
+	for _, row := range detailRows(d, false) {
+		t.Append(row)
+	}
+	t.Render()
+
+	if tags := resourceTags(d.Resource); len(tags) > 0 {
+		pterm.DefaultSection.WithLevel(2).Println("Tags")
+		keys := make([]string, 0, len(tags))
+		for k := range tags {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		t := output.NewTable([]string{"Key", "Value"})
+		for _, k := range keys {
+			t.Append([]string{k, tags[k]})
+		}
+		t.Render()
+	}
+
+	if len(d.Related) > 0 {
+		pterm.DefaultSection.WithLevel(2).Println("Related Resources")
+		t := output.NewTable([]string{"Service", "ID", "Field", "Value", "Command"})
+		for _, r := range d.Related {
+			t.Append([]string{r.Service, r.ID, r.Field, r.Value, r.Command})
+		}
+		t.Render()
+	}
+}
+
+// detailRows lists the fields of a described resource as key/value rows.
+// Tags are listed one per row when withTags is set, and left out otherwise
+func detailRows(d Description, withTags bool) [][]string {
+	rows := [][]string{
+		{"Account", d.Account},
+		{"Region", d.Region},
+	}
+
+	val := reflect.ValueOf(d.Resource)
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Name == "Tags" {
+			if withTags {
+				tags := resourceTags(d.Resource)
+				keys := make([]string, 0, len(tags))
+				for k := range tags {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				for _, k := range keys {
+					rows = append(rows, []string{"Tags." + k, tags[k]})
+				}
+			}
+			continue
+		}
+
+		header := field.Tag.Get("header")
+		if header == "" {
+			header = field.Name
+		}
+		rows = append(rows, []string{header, formatDetail(val.Field(i).Interface())})
+	}
+	return rows
+}
+
+func resourceTags(v any) map[string]string {
+	f := reflect.ValueOf(v).FieldByName("Tags")
+	if !f.IsValid() {
+		return nil
+	}
+	tags, _ := f.Interface().(map[string]string)
+	return tags
+}
+
+// formatDetail renders a field value for the describe view, with one line
+// per map entry or slice element
+func formatDetail(v any) string {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map:
+		lines := make([]string, 0, rv.Len())
+		for _, k := range rv.MapKeys() {
+			lines = append(lines, fmt.Sprintf("%v: %s", k.Interface(), formatDetail(rv.MapIndex(k).Interface())))
+		}
+		sort.Strings(lines)
+		return strings.Join(lines, "\n")
+
+	case reflect.Slice:
+		lines := make([]string, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			lines = append(lines, formatDetail(rv.Index(i).Interface()))
+		}
+		return strings.Join(lines, "\n")
+
+	case reflect.Struct:
+		typ := rv.Type()
+		parts := make([]string, 0, typ.NumField())
+		for i := 0; i < typ.NumField(); i++ {
+			if typ.Field(i).IsExported() {
+				parts = append(parts, fmt.Sprintf("%s=%s", typ.Field(i).Name, formatCell(rv.Field(i).Interface())))
+			}
+		}
+		return strings.Join(parts, ", ")
+	}
+	return formatCell(v)
+}
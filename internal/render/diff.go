@@ -0,0 +1,378 @@
+package render
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/sunil-saini/astat/internal/cache"
+	"github.com/sunil-saini/astat/internal/logger"
+	"github.com/sunil-saini/astat/internal/model"
+	"github.com/sunil-saini/astat/internal/output"
+	"github.com/sunil-saini/astat/internal/refresh"
+	"github.com/sunil-saini/astat/internal/registry"
+)
+
+const (
+	changeAdded   = "added"
+	changeRemoved = "removed"
+	changeChanged = "changed"
+)
+
+// Change is a resource added, removed or changed between two versions of
+// the cached data of a service
+type Change struct {
+	Change   string        `json:"change"`
+	Service  string        `json:"service"`
+	Account  string        `json:"account"`
+	Region   string        `json:"region"`
+	ID       string        `json:"id"`
+	Fields   []FieldChange `json:"fields,omitempty"`
+	Resource any           `json:"resource"`
+
+	it item
+}
+
+// FieldChange is a field of a changed resource, with its old and new value
+type FieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// DiffOptions selects the versions of the cached data Diff compares: the
+// latest snapshots taken at or before From, or Since ago, and To, or the
+// current cache when To is empty
+type DiffOptions struct {
+	Since time.Duration
+	From  string
+	To    string
+}
+
+// Diff prints the resources of a service added, removed or changed between
+// two versions of its cached data, telling them apart by the service's ID
+// field, in every account and region the service is listed from
+func Diff(ctx context.Context, serviceName string, opts DiffOptions) error {
+	svc, err := getService(serviceName)
+	if err != nil {
+		return err
+	}
+
+	from := time.Now().Add(-opts.Since)
+	if opts.From != "" {
+		if from, err = cache.ParseSnapshotTime(opts.From); err != nil {
+			return fmt.Errorf("--from: %w", err)
+		}
+	}
+	var to time.Time
+	if opts.To != "" {
+		if to, err = cache.ParseSnapshotTime(opts.To); err != nil {
+			return fmt.Errorf("--to: %w", err)
+		}
+		if !to.After(from) {
+			return fmt.Errorf("--to must be later than --from")
+		}
+	}
+
+	idColumn, err := lookupColumn(svc.Model, svc.IDField)
+	if err != nil {
+		return err
+	}
+	columns := modelColumns(svc.Model, true)
+
+	contexts, err := refresh.Contexts(ctx, serviceName)
+	if err != nil {
+		return err
+	}
+
+	var changes []Change
+	compared := 0
+	for _, c := range contexts {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		dir := cache.ContextDir(c)
+		snapshots, err := cache.Snapshots(dir, svc.Name)
+		if err != nil {
+			return err
+		}
+		if len(snapshots) == 0 {
+			logger.Warn("%s: no snapshot of %s yet, they are taken on every refresh", c, svc.Name)
+			continue
+		}
+
+		old := snapshotAt(snapshots, from)
+		if old == nil {
+			old = &snapshots[0]
+			logger.Warn("%s: no snapshot of %s that old, comparing with the oldest one", c, svc.Name)
+		}
+		newPath, newName := cache.Path(dir, svc.Name), "the current cache"
+		if opts.To != "" {
+			s := snapshotAt(snapshots, to)
+			if s == nil {
+				logger.Warn("%s: no snapshot of %s at or before --to", c, svc.Name)
+				continue
+			}
+			newPath, newName = s.Path, "snapshot "+s.Name
+		}
+		logger.Info("%s: comparing snapshot %s with %s", c, old.Name, newName)
+
+		before, err := loadVersion(svc, old.Path, c)
+		if err != nil {
+			return err
+		}
+		after, err := loadVersion(svc, newPath, c)
+		if err != nil {
+			return err
+		}
+		changes = append(changes, diffItems(svc, idColumn, columns, before, after)...)
+		compared++
+	}
+	if compared == 0 {
+		return fmt.Errorf("no history of %s to compare, run 'astat refresh %s' to take a snapshot", serviceName, serviceName)
+	}
+
+	format, _, err := output.ParseFormat(viper.GetString("output"))
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 && format == output.Table {
+		logger.Info("No %s changes", serviceName)
+		return nil
+	}
+
+	ctxColumns := contextColumns(contexts)
+	headers := []string{"Change"}
+	for _, col := range ctxColumns {
+		headers = append(headers, col.header)
+	}
+	headers = append(headers, "ID", "Changes")
+
+	counts := make(map[string]int)
+	rows := make([][]string, 0, len(changes))
+	data := make([]any, 0, len(changes))
+	for _, ch := range changes {
+		counts[ch.Change]++
+		row := []string{ch.Change}
+		for _, col := range ctxColumns {
+			row = append(row, col.cell(ch.it))
+		}
+		rows = append(rows, append(row, ch.ID, describeFieldChanges(ch.Fields)))
+		data = append(data, ch)
+	}
+
+	if err := Print(TableData{Headers: headers, Rows: rows, JSON: data}); err != nil {
+		return err
+	}
+	if format == output.Table {
+		logger.Info("%d added, %d removed, %d changed", counts[changeAdded], counts[changeRemoved], counts[changeChanged])
+	}
+	return nil
+}
+
+// Snapshots lists the snapshots of a service in every account and region it
+// is listed from
+func Snapshots(ctx context.Context, serviceName string) error {
+	svc, err := getService(serviceName)
+	if err != nil {
+		return err
+	}
+	contexts, err := refresh.Contexts(ctx, serviceName)
+	if err != nil {
+		return err
+	}
+
+	type snapshotInfo struct {
+		Account   string    `json:"account"`
+		Region    string    `json:"region"`
+		Name      string    `json:"name"`
+		Time      time.Time `json:"time"`
+		Resources int       `json:"resources"`
+	}
+
+	ctxColumns := contextColumns(contexts)
+	headers := make([]string, 0, len(ctxColumns)+3)
+	for _, col := range ctxColumns {
+		headers = append(headers, col.header)
+	}
+	headers = append(headers, "Snapshot", "Taken", "Resources")
+
+	var rows [][]string
+	var data []any
+	for _, c := range contexts {
+		snapshots, err := cache.Snapshots(cache.ContextDir(c), svc.Name)
+		if err != nil {
+			return err
+		}
+		for _, s := range snapshots {
+			items, err := loadVersion(svc, s.Path, c)
+			if err != nil {
+				return err
+			}
+
+			row := make([]string, 0, len(headers))
+			for _, col := range ctxColumns {
+				row = append(row, col.cell(item{cctx: c}))
+			}
+			rows = append(rows, append(row, s.Name, s.Time.Local().Format("2006-01-02 15:04:05"), fmt.Sprint(len(items))))
+			data = append(data, snapshotInfo{Account: c.AccountID, Region: c.Region, Name: s.Name, Time: s.Time, Resources: len(items)})
+		}
+	}
+	if len(rows) == 0 {
+		logger.Warn("No snapshot of %s yet, they are taken on every refresh", serviceName)
+		return nil
+	}
+
+	return Print(TableData{Headers: headers, Rows: rows, JSON: data})
+}
+
+// snapshotAt returns the latest snapshot taken at or before t
+func snapshotAt(snapshots []cache.Snapshot, t time.Time) *cache.Snapshot {
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		if !snapshots[i].Time.After(t) {
+			return &snapshots[i]
+		}
+	}
+	return nil
+}
+
+// loadVersion loads the resources of a cache file or snapshot
+func loadVersion(svc *registry.Service, path string, cctx cache.Context) ([]item, error) {
+	dataPtr, hit, err := loadFile(svc, path)
+	if err != nil {
+		return nil, err
+	}
+	if !hit {
+		return nil, fmt.Errorf("cannot read %s", path)
+	}
+
+	var items []item
+	for _, v := range model.ToAnySlice(dataPtr.Elem().Interface()) {
+		items = append(items, item{value: v, cctx: cctx})
+	}
+	return items, nil
+}
+
+// diffItems returns the resources added, removed or changed from before to
+// after, keyed by their context and identity
+func diffItems(svc *registry.Service, idColumn column, columns []column, before, after []item) []Change {
+	old := make(map[string]item, len(before))
+	for _, it := range before {
+		old[itemKey(svc, idColumn, it)] = it
+	}
+
+	var changes []Change
+	seen := make(map[string]bool, len(after))
+	for _, it := range after {
+		id := idColumn.cell(it)
+		seen[itemKey(svc, idColumn, it)] = true
+
+		prev, ok := old[itemKey(svc, idColumn, it)]
+		if !ok {
+			changes = append(changes, newChange(svc, changeAdded, id, it))
+			continue
+		}
+
+		var fields []FieldChange
+		for _, col := range columns {
+			from, to := col.value(prev), col.value(it)
+			if reflect.DeepEqual(from, to) || (isEmptyValue(from) && isEmptyValue(to)) {
+				continue
+			}
+			fields = append(fields, FieldChange{Field: col.header, From: from, To: to})
+		}
+		if len(fields) > 0 {
+			ch := newChange(svc, changeChanged, id, it)
+			ch.Fields = fields
+			changes = append(changes, ch)
+		}
+	}
+	for _, it := range before {
+		if !seen[itemKey(svc, idColumn, it)] {
+			changes = append(changes, newChange(svc, changeRemoved, idColumn.cell(it), it))
+		}
+	}
+
+	order := []string{changeAdded, changeRemoved, changeChanged}
+	slices.SortStableFunc(changes, func(a, b Change) int {
+		return cmp.Or(
+			cmp.Compare(slices.Index(order, a.Change), slices.Index(order, b.Change)),
+			compareStrings(a.ID, b.ID),
+		)
+	})
+	return changes
+}
+
+// itemKey identifies an item across versions of the data, IDs being unique
+// within a context only. Services whose ID is not unique have their own key
+func itemKey(svc *registry.Service, idColumn column, it item) string {
+	if svc.Key != nil {
+		return it.cctx.String() + "|" + svc.Key(it.value)
+	}
+	return it.cctx.String() + "|" + idColumn.cell(it)
+}
+
+func newChange(svc *registry.Service, change, id string, it item) Change {
+	return Change{
+		Change:   change,
+		Service:  svc.Name,
+		Account:  it.cctx.AccountID,
+		Region:   it.cctx.Region,
+		ID:       id,
+		Resource: it.value,
+		it:       it,
+	}
+}
+
+// isEmptyValue reports whether a value is a nil or empty slice or map, so a
+// field cached as null and later as empty does not count as a change
+func isEmptyValue(v any) bool {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	case reflect.Invalid:
+		return true
+	}
+	return false
+}
+
+// describeFieldChanges summarizes field changes for a table cell, naming
+// only the fields too nested to be shown inline
+func describeFieldChanges(fields []FieldChange) string {
+	parts := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if !isInline(f.From) || !isInline(f.To) {
+			parts = append(parts, f.Field+" changed")
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s → %s", f.Field, valueOrDash(formatCell(f.From)), valueOrDash(formatCell(f.To))))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// isInline reports whether formatCell renders a value readably
+func isInline(v any) bool {
+	switch v.(type) {
+	case []string, map[string]string, time.Time:
+		return true
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct, reflect.Pointer, reflect.Interface:
+		return false
+	}
+	return true
+}
+
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,99 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/sunil-saini/astat/internal/cache"
+	"github.com/sunil-saini/astat/internal/model"
+)
+
+func TestDiffItemsDuplicateRecordNames(t *testing.T) {
+	svc, err := getService("route53-records")
+	if err != nil {
+		t.Fatal(err)
+	}
+	idColumn, err := lookupColumn(svc.Model, svc.IDField)
+	if err != nil {
+		t.Fatal(err)
+	}
+	columns := modelColumns(svc.Model, true)
+
+	cctx := cache.Context{AccountID: "123456789012", Profile: "default", Region: "global"}
+	items := func(records ...model.Route53Record) []item {
+		var items []item
+		for _, r := range records {
+			items = append(items, item{value: r, cctx: cctx})
+		}
+		return items
+	}
+	record := func(zone, typ, setID, value string) model.Route53Record {
+		return model.Route53Record{ZoneName: zone, Name: "api.example.com.", Type: typ, TTL: "60", Value: value, SetIdentifier: setID}
+	}
+
+	before := items(
+		record("example.com.", "A", "blue", "10.0.0.1"),
+		record("example.com.", "A", "green", "10.0.0.2"),
+		record("example.com.", "TXT", "", "\"v=1\""),
+		record("internal.example.com.", "A", "", "10.1.0.1"),
+	)
+	after := items(
+		record("example.com.", "A", "blue", "10.0.0.1"),
+		record("example.com.", "A", "green", "10.0.0.3"),
+		record("example.com.", "AAAA", "", "::1"),
+		record("internal.example.com.", "A", "", "10.1.0.1"),
+	)
+
+	changes := diffItems(svc, idColumn, columns, before, after)
+
+	type summary struct {
+		change, typ, setID string
+	}
+	var got []summary
+	for _, c := range changes {
+		r := c.Resource.(model.Route53Record)
+		got = append(got, summary{c.Change, r.Type, r.SetIdentifier})
+		if c.ID != "api.example.com." {
+			t.Errorf("change ID = %q, want the record name", c.ID)
+		}
+	}
+	want := []summary{
+		{changeAdded, "AAAA", ""},
+		{changeRemoved, "TXT", ""},
+		{changeChanged, "A", "green"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("changes = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("change %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	changed := changes[len(changes)-1]
+	if len(changed.Fields) != 1 || changed.Fields[0].Field != "Value" {
+		t.Errorf("changed fields = %+v, want only Value", changed.Fields)
+	}
+}
+
+func TestDiffItemsUnchanged(t *testing.T) {
+	svc, err := getService("route53-records")
+	if err != nil {
+		t.Fatal(err)
+	}
+	idColumn, err := lookupColumn(svc.Model, svc.IDField)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cctx := cache.Context{AccountID: "123456789012", Profile: "default", Region: "global"}
+	records := []item{
+		{value: model.Route53Record{ZoneName: "example.com.", Name: "www.example.com.", Type: "A", Value: "10.0.0.1", SetIdentifier: "eu"}, cctx: cctx},
+		{value: model.Route53Record{ZoneName: "example.com.", Name: "www.example.com.", Type: "A", Value: "10.0.0.2", SetIdentifier: "us"}, cctx: cctx},
+	}
+	reversed := []item{records[1], records[0]}
+
+	if changes := diffItems(svc, idColumn, modelColumns(svc.Model, true), records, reversed); len(changes) != 0 {
+		t.Errorf("changes = %+v, want none", changes)
+	}
+}
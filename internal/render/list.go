@@ -2,13 +2,16 @@ package render
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"slices"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/sunil-saini/astat/internal/cache"
+	"github.com/sunil-saini/astat/internal/filter"
 	"github.com/sunil-saini/astat/internal/logger"
 	"github.com/sunil-saini/astat/internal/model"
 	"github.com/sunil-saini/astat/internal/refresh"
@@ -16,10 +19,13 @@ import (
 )
 
 // List handles the common logic for listing resources
-// 1. loading from cache
+// 1. loading from cache, for every account and region the service is served from
 // 2. refreshing if needed
-// 3. automatically extracting headers and rows from the model
-// 4. filtering by search term if provided in args
+// 3. automatically extracting headers and rows from the model, or the fields
+// picked with --columns / --wide
+// 4. filtering by the --filter expression, the --tag / --tag-key tags and the
+// search term if provided in args
+// 5. sorting by the --sort-by field
 func List(
 	cmd *cobra.Command,
 	args []string,
@@ -30,28 +36,101 @@ func List(
 		return err
 	}
 
-	dataPtr, hit, err := loadCache(cmd.Context(), service)
+	f, err := filter.Parse(viper.GetString("filter"))
+	if err != nil {
+		return fmt.Errorf("--filter: %w", err)
+	}
+
+	matchTags, err := tagMatcher(service.Model)
+	if err != nil {
+		return fmt.Errorf("--tag: %w", err)
+	}
+
+	contexts, err := refresh.Contexts(cmd.Context(), serviceName)
 	if err != nil {
 		return err
 	}
 
-	if viper.GetBool("refresh") || !hit {
-		refresh.RefreshSync(cmd.Context(), serviceName, service.Fetch)
-		dataPtr, _, err = loadCache(cmd.Context(), service)
+	filterColumns := make(map[string]column)
+	for _, name := range f.Fields() {
+		col, err := lookupColumn(service.Model, name)
 		if err != nil {
-			return err
+			return fmt.Errorf("--filter: %w", err)
 		}
+		filterColumns[name] = col
+	}
+
+	sortColumn, desc, err := sortBy(service.Model, viper.GetString("sort-by"))
+	if err != nil {
+		return fmt.Errorf("--sort-by: %w", err)
+	}
+
+	columns, err := tableColumns(service.Model, contexts)
+	if err != nil {
+		return fmt.Errorf("--columns: %w", err)
+	}
+
+	watchInterval, err := parseWatch(viper.GetString("watch"))
+	if err != nil {
+		return fmt.Errorf("--watch: %w", err)
 	}
 
-	headers, fields := extractHeaders(service.Model)
-	items := model.ToAnySlice(dataPtr.Elem().Interface())
+	headers := make([]string, 0, len(columns))
+	for _, col := range columns {
+		headers = append(headers, col.header)
+	}
 
 	searchTerm := ""
 	if len(args) > 0 {
 		searchTerm = strings.ToLower(args[0])
 	}
+	searched := searchColumns(service.Model, columns)
+
+	// view keeps the items to list, in the order to list them
+	view := func(items []item) []item {
+		if !f.Empty() {
+			items = slices.DeleteFunc(items, func(it item) bool {
+				return !f.Match(func(field string) (string, bool) {
+					return filterColumns[field].cell(it), true
+				})
+			})
+		}
+
+		if matchTags != nil {
+			items = slices.DeleteFunc(items, func(it item) bool { return !matchTags(it) })
+		}
+
+		if searchTerm != "" {
+			items = slices.DeleteFunc(items, func(it item) bool {
+				_, _, ok := matchColumn(it, searched, searchTerm)
+				return !ok
+			})
+		}
+
+		if sortColumn != nil {
+			sortItems(items, *sortColumn, desc)
+		}
+		return items
+	}
+
+	if watchInterval > 0 {
+		return watch(cmd.Context(), service, watchInterval, view, headers, columns, contextColumns(contexts))
+	}
 
-	rows, filteredData := filterRows(items, fields, searchTerm)
+	items, hit, err := loadItems(cmd.Context(), service, contexts)
+	if err != nil {
+		return err
+	}
+
+	if viper.GetBool("refresh") || !hit {
+		refresh.RefreshSync(cmd.Context(), serviceName, service.Fetch)
+		items, _, err = loadItems(cmd.Context(), service, contexts)
+		if err != nil {
+			return err
+		}
+	}
+
+	rows, filteredData := tableRows(view(items), columns, contextColumns(contexts))
 
 	return Print(TableData{
 		Headers: headers,
@@ -60,6 +139,33 @@ func List(
 	})
 }
 
+// item is a cached resource along with the context it was loaded from
+type item struct {
+	value any
+	cctx  cache.Context
+}
+
+// loadItems loads the cached data of a service from every context, reporting
+// a hit only when all of them are cached
+func loadItems(ctx context.Context, service *registry.Service, contexts []cache.Context) ([]item, bool, error) {
+	var items []item
+	allHit := true
+	for _, c := range contexts {
+		dataPtr, hit, err := loadCache(ctx, service, c)
+		if err != nil {
+			return nil, false, err
+		}
+		if !hit {
+			allHit = false
+			continue
+		}
+		for _, v := range model.ToAnySlice(dataPtr.Elem().Interface()) {
+			items = append(items, item{value: v, cctx: c})
+		}
+	}
+	return items, allHit, nil
+}
+
 func getService(name string) (*registry.Service, error) {
 	for _, s := range registry.Registry {
 		if s.Name == name {
@@ -69,16 +175,21 @@ func getService(name string) (*registry.Service, error) {
 	return nil, fmt.Errorf("unknown service: %s", name)
 }
 
-func loadCache(ctx context.Context, service *registry.Service) (reflect.Value, bool, error) {
+func loadCache(ctx context.Context, service *registry.Service, cctx cache.Context) (reflect.Value, bool, error) {
 	if err := ctx.Err(); err != nil {
 		return reflect.Value{}, false, err
 	}
 
-	cacheFile := cache.Path(cache.Dir(), service.Name)
+	return loadFile(service, cache.Path(cache.ContextDir(cctx), service.Name))
+}
+
+// loadFile loads a cache file, or a snapshot, of a service into a pointer to
+// a slice of its model
+func loadFile(service *registry.Service, path string) (reflect.Value, bool, error) {
 	sliceType := reflect.SliceOf(reflect.TypeOf(service.Model))
 	dataPtr := reflect.New(sliceType)
 
-	hit, err := cache.Load(cacheFile, dataPtr.Interface())
+	hit, err := cache.Load(path, dataPtr.Interface())
 	if err != nil {
 		logger.Error("cache read failed: %v", err)
 		return reflect.Value{}, false, err
@@ -86,44 +197,41 @@ func loadCache(ctx context.Context, service *registry.Service) (reflect.Value, b
 	return dataPtr, hit, nil
 }
 
-func extractHeaders(m any) ([]string, []int) {
-	var headers []string
-	var fields []int
-
-	typ := reflect.TypeOf(m)
-	for i := 0; i < typ.NumField(); i++ {
-		field := typ.Field(i)
-		if header := field.Tag.Get("header"); header != "" {
-			headers = append(headers, header)
-			fields = append(fields, i)
-		}
+// tableRows returns the table row and the JSON representation of each item
+func tableRows(items []item, columns, contextColumns []column) ([][]string, []any) {
+	var data []any
+	rows := make([][]string, 0, len(items))
+	for _, it := range items {
+		rows = append(rows, rowCells(it, columns))
+		data = append(data, annotate(it, contextColumns))
 	}
-	return headers, fields
+	return rows, data
 }
 
-func filterRows(items []any, fields []int, searchTerm string) ([][]string, []any) {
-	var filteredData []any
-	rows := make([][]string, 0)
-
-	for _, item := range items {
-		row := make([]string, 0, len(fields))
-		val := reflect.ValueOf(item)
-		match := searchTerm == ""
-
-		for _, idx := range fields {
-			fieldVal := val.Field(idx)
-			cell := fmt.Sprintf("%v", fieldVal.Interface())
-			row = append(row, cell)
+func rowCells(it item, columns []column) []string {
+	row := make([]string, 0, len(columns))
+	for _, col := range columns {
+		row = append(row, col.cell(it))
+	}
+	return row
+}
 
-			if !match && strings.Contains(strings.ToLower(cell), searchTerm) {
-				match = true
-			}
-		}
+// annotate adds the context columns to the JSON representation of an item
+func annotate(it item, columns []column) any {
+	if len(columns) == 0 {
+		return it.value
+	}
 
-		if match {
-			rows = append(rows, row)
-			filteredData = append(filteredData, item)
-		}
+	data, err := json.Marshal(it.value)
+	if err != nil {
+		return it.value
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return it.value
+	}
+	for _, col := range columns {
+		fields[col.header] = col.cell(it)
 	}
-	return rows, filteredData
+	return fields
 }
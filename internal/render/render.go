@@ -2,7 +2,6 @@ package render
 
 import (
 	"fmt"
-
 	"github.com/spf13/viper"
 	"github.com/sunil-saini/astat/internal/output"
 )
@@ -14,19 +13,42 @@ type TableData struct {
 }
 
 func Print(d TableData) error {
-	switch viper.GetString("output") {
-	case "json":
+	format, arg, err := output.ParseFormat(viper.GetString("output"))
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case output.JSON:
 		return output.PrintJSON(d.JSON)
 
-	case "table", "":
+	case output.NDJSON:
+		return output.PrintNDJSON(d.JSON)
+
+	case output.YAML:
+		return output.PrintYAML(d.JSON)
+
+	case output.GoTemplate, output.GoTemplateFile, output.JSONPathTemplate, output.JSONPathFile:
+		return output.PrintTemplate(format, arg, d.JSON)
+
+	case output.CSV:
+		return output.PrintCSV(d.Headers, d.Rows)
+
+	case output.TSV:
+		return output.PrintTSV(d.Headers, d.Rows)
+
+	case output.Markdown:
+		return output.PrintMarkdown(d.Headers, d.Rows)
+
+	case output.DOT, output.Mermaid:
+		return fmt.Errorf("output format %s is only supported by domain trace", format)
+
+	default:
 		t := output.NewTable(d.Headers)
 		for _, row := range d.Rows {
 			t.Append(row)
 		}
 		t.Render()
 		return nil
-
-	default:
-		return fmt.Errorf("unknown output format")
 	}
 }
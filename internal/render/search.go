@@ -0,0 +1,143 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/sunil-saini/astat/internal/logger"
+	"github.com/sunil-saini/astat/internal/refresh"
+	"github.com/sunil-saini/astat/internal/registry"
+)
+
+// SearchResult is a cached resource matching a search term
+type SearchResult struct {
+	Service  string `json:"service"`
+	Account  string `json:"account"`
+	Region   string `json:"region"`
+	ID       string `json:"id"`
+	Field    string `json:"field"`
+	Value    string `json:"value"`
+	Command  string `json:"command"`
+	Resource any    `json:"resource"`
+}
+
+// Search looks for a term in every field of the cached data of all services
+// at once, and prints the matching resources grouped by service along with
+// the field that matched and the command showing the resource
+func Search(ctx context.Context, term string) error {
+	searchTerm := strings.ToLower(term)
+
+	found := make([][]SearchResult, len(registry.Registry))
+	var wg sync.WaitGroup
+	for i, svc := range registry.Registry {
+		wg.Go(func() {
+			results, err := searchService(ctx, svc, searchTerm)
+			if err != nil {
+				logger.Warn("search skipped %s: %v", svc.Name, err)
+				return
+			}
+			found[i] = results
+		})
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var results []SearchResult
+	for _, r := range found {
+		results = append(results, r...)
+	}
+	if len(results) == 0 {
+		logger.Warn("No cached resources match %q, run 'astat refresh' if the cache is out of date", term)
+		return nil
+	}
+
+	accounts := make(map[string]bool)
+	regions := make(map[string]bool)
+	for _, r := range results {
+		accounts[r.Account] = true
+		regions[r.Region] = true
+	}
+
+	headers := []string{"Service"}
+	if len(accounts) > 1 {
+		headers = append(headers, "Account")
+	}
+	if len(regions) > 1 {
+		headers = append(headers, "Region")
+	}
+	headers = append(headers, "ID", "Field", "Value", "Command")
+
+	rows := make([][]string, 0, len(results))
+	data := make([]any, 0, len(results))
+	for _, r := range results {
+		row := []string{r.Service}
+		if len(accounts) > 1 {
+			row = append(row, r.Account)
+		}
+		if len(regions) > 1 {
+			row = append(row, r.Region)
+		}
+		rows = append(rows, append(row, r.ID, r.Field, r.Value, r.Command))
+		data = append(data, r)
+	}
+
+	return Print(TableData{
+		Headers: headers,
+		Rows:    rows,
+		JSON:    data,
+	})
+}
+
+// searchService returns the cached resources of a service with a field
+// containing the lower case search term
+func searchService(ctx context.Context, svc registry.Service, searchTerm string) ([]SearchResult, error) {
+	contexts, err := refresh.Contexts(ctx, svc.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	items, _, err := loadItems(ctx, &svc, contexts)
+	if err != nil {
+		return nil, err
+	}
+
+	idColumn, err := lookupColumn(svc.Model, svc.IDField)
+	if err != nil {
+		return nil, err
+	}
+	columns := modelColumns(svc.Model, true)
+
+	var results []SearchResult
+	for _, it := range items {
+		col, cell, ok := matchColumn(it, columns, searchTerm)
+		if !ok {
+			continue
+		}
+
+		id := idColumn.cell(it)
+		results = append(results, SearchResult{
+			Service:  svc.Name,
+			Account:  it.cctx.AccountID,
+			Region:   it.cctx.Region,
+			ID:       id,
+			Field:    col.header,
+			Value:    cell,
+			Command:  drillInCommand(svc, id),
+			Resource: it.value,
+		})
+	}
+	return results, nil
+}
+
+// drillInCommand returns the command listing a single resource
+func drillInCommand(svc registry.Service, id string) string {
+	if strings.ContainsAny(id, ",&|() ") {
+		id = `"` + id + `"`
+	}
+	return fmt.Sprintf("astat %s --filter '%s=%s'", svc.Command, svc.IDField, id)
+}
@@ -0,0 +1,133 @@
+package render
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// tagPrefixes introduce a tag key wherever a field name is expected, e.g.
+// --columns tag:team or --filter 'tags.env=prod'
+var tagPrefixes = []string{"tag:", "tags."}
+
+// tagsField returns the index of the Tags field of a model
+func tagsField(m any) (int, bool) {
+	field, ok := reflect.TypeOf(m).FieldByName("Tags")
+	if !ok || field.Type != reflect.TypeOf(map[string]string(nil)) {
+		return 0, false
+	}
+	return field.Index[0], true
+}
+
+func resourceTagMap(idx int, it item) map[string]string {
+	return reflect.ValueOf(it.value).Field(idx).Interface().(map[string]string)
+}
+
+// tagValue looks up a tag key, exactly or else case insensitively
+func tagValue(tags map[string]string, key string) (string, bool) {
+	if v, ok := tags[key]; ok {
+		return v, true
+	}
+	for k, v := range tags {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// tagKey strips a tag prefix from a field name
+func tagKey(name string) (string, bool) {
+	for _, prefix := range tagPrefixes {
+		if len(name) > len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+			return name[len(prefix):], true
+		}
+	}
+	return "", false
+}
+
+// tagColumn returns a column with the value of a tag, headed by its key
+func tagColumn(m any, key string) (column, error) {
+	idx, ok := tagsField(m)
+	if !ok {
+		return column{}, fmt.Errorf("%s resources have no tags", reflect.TypeOf(m).Name())
+	}
+	return column{
+		header: key,
+		value: func(it item) any {
+			v, _ := tagValue(resourceTagMap(idx, it), key)
+			return v
+		},
+	}, nil
+}
+
+// promotedTagColumns returns a column per tag key configured in tag-columns,
+// skipping the keys that clash with a column already shown
+func promotedTagColumns(m any, columns []column) []column {
+	if _, ok := tagsField(m); !ok {
+		return nil
+	}
+
+	var promoted []column
+	for _, key := range viper.GetStringSlice("tag-columns") {
+		key = strings.TrimSpace(key)
+		if key == "" || hasHeader(columns, key) || hasHeader(promoted, key) {
+			continue
+		}
+		col, _ := tagColumn(m, key)
+		promoted = append(promoted, col)
+	}
+	return promoted
+}
+
+func hasHeader(columns []column, header string) bool {
+	for _, col := range columns {
+		if normalizeField(col.header) == normalizeField(header) {
+			return true
+		}
+	}
+	return false
+}
+
+// tagMatcher returns a predicate keeping the items carrying every --tag
+// key=value pair, values compared case insensitively, and every --tag-key key.
+// It returns nil when neither flag is set
+func tagMatcher(m any) (func(it item) bool, error) {
+	pairs := viper.GetStringSlice("tag")
+	keys := viper.GetStringSlice("tag-key")
+	if len(pairs) == 0 && len(keys) == 0 {
+		return nil, nil
+	}
+
+	idx, ok := tagsField(m)
+	if !ok {
+		return nil, fmt.Errorf("%s resources have no tags", reflect.TypeOf(m).Name())
+	}
+
+	want := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("invalid tag %q, expected key=value", pair)
+		}
+		want[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+
+	return func(it item) bool {
+		tags := resourceTagMap(idx, it)
+		for k, v := range want {
+			got, ok := tagValue(tags, k)
+			if !ok || !strings.EqualFold(got, v) {
+				return false
+			}
+		}
+		for _, k := range keys {
+			if _, ok := tagValue(tags, strings.TrimSpace(k)); !ok {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
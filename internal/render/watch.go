@@ -0,0 +1,220 @@
+package render
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/viper"
+	"github.com/sunil-saini/astat/internal/logger"
+	"github.com/sunil-saini/astat/internal/output"
+	"github.com/sunil-saini/astat/internal/refresh"
+	"github.com/sunil-saini/astat/internal/registry"
+	"golang.org/x/term"
+)
+
+// DefaultWatchInterval is the interval of --watch given without one
+const DefaultWatchInterval = "5s"
+
+const minWatchInterval = time.Second
+
+var (
+	addedStyle   = color.New(color.FgHiGreen)
+	changedStyle = color.New(color.FgHiYellow, color.Bold)
+	removedStyle = color.New(color.FgHiRed, color.Faint)
+)
+
+// watchEvent is a change reported by --watch when stdout is not a terminal
+type watchEvent struct {
+	Time time.Time `json:"time"`
+	Change
+}
+
+// parseWatch parses the --watch interval, 0 meaning not to watch
+func parseWatch(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < minWatchInterval {
+		return 0, fmt.Errorf("interval must be at least %s", minWatchInterval)
+	}
+	return d, nil
+}
+
+// watch fetches a service live every interval, without caching it, and lists
+// the items kept by view. In a terminal the list is redrawn in place with the
+// rows added, removed or changed since the previous fetch highlighted,
+// otherwise only those changes are printed as they happen
+func watch(ctx context.Context, service *registry.Service, interval time.Duration, view func([]item) []item, headers []string, columns, ctxColumns []column) error {
+	idColumn, err := lookupColumn(service.Model, service.IDField)
+	if err != nil {
+		return err
+	}
+	format, _, err := output.ParseFormat(viper.GetString("output"))
+	if err != nil {
+		return err
+	}
+	interactive := term.IsTerminal(int(os.Stdout.Fd()))
+	allColumns := modelColumns(service.Model, true)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var prev []item
+	fetched := false
+	for {
+		resources, fetchErr := refresh.Fetch(ctx, service.Name)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		items := prev
+		if fetchErr == nil {
+			items = make([]item, 0, len(resources))
+			for _, r := range resources {
+				items = append(items, item{value: r.Value, cctx: r.Context})
+			}
+			items = view(items)
+		}
+
+		switch {
+		case interactive:
+			if err := redraw(interval, format, headers, columns, ctxColumns, service, idColumn, prev, items, fetched, fetchErr); err != nil {
+				return err
+			}
+		case fetchErr != nil:
+			logger.Error("fetch failed: %v", fetchErr)
+		case !fetched:
+			logger.Info("Watching %d %s resources every %s", len(items), service.Name, interval)
+		default:
+			if err := printEvents(os.Stdout, format, diffItems(service, idColumn, allColumns, prev, items), columns); err != nil {
+				return err
+			}
+		}
+		if fetchErr == nil {
+			prev, fetched = items, true
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// redraw clears the terminal and lists the items, highlighting the rows and
+// cells that differ from the previous items when there are any
+func redraw(interval time.Duration, format output.Format, headers []string, columns, ctxColumns []column, service *registry.Service, idColumn column, prev, items []item, compare bool, fetchErr error) error {
+	fmt.Print("\033[H\033[2J")
+	fmt.Printf("Every %s: astat %s    %s\n\n", interval, strings.Join(os.Args[1:], " "), time.Now().Format("15:04:05"))
+	if fetchErr != nil {
+		logger.Error("fetch failed, showing the previous data: %v", fetchErr)
+	}
+
+	if format != output.Table {
+		rows, data := tableRows(items, columns, ctxColumns)
+		return Print(TableData{Headers: headers, Rows: rows, JSON: data})
+	}
+
+	prevRows := make(map[string][]string, len(prev))
+	for _, it := range prev {
+		prevRows[itemKey(service, idColumn, it)] = rowCells(it, columns)
+	}
+
+	t := output.NewTable(headers)
+	seen := make(map[string]bool, len(items))
+	added, changed, removed := 0, 0, 0
+	for _, it := range items {
+		key := itemKey(service, idColumn, it)
+		seen[key] = true
+		row := rowCells(it, columns)
+
+		old, ok := prevRows[key]
+		switch {
+		case !compare || fetchErr != nil:
+		case !ok:
+			added++
+			for i := range row {
+				row[i] = addedStyle.Sprint(row[i])
+			}
+		default:
+			rowChanged := false
+			for i := range row {
+				if i < len(old) && row[i] != old[i] {
+					row[i] = changedStyle.Sprint(row[i])
+					rowChanged = true
+				}
+			}
+			if rowChanged {
+				changed++
+			}
+		}
+		t.Append(row)
+	}
+	if compare && fetchErr == nil {
+		for _, it := range prev {
+			if seen[itemKey(service, idColumn, it)] {
+				continue
+			}
+			removed++
+			row := rowCells(it, columns)
+			for i := range row {
+				row[i] = removedStyle.Sprint(row[i])
+			}
+			t.Append(row)
+		}
+	}
+	t.Render()
+
+	fmt.Printf("\n%d resources", len(items))
+	if compare && fetchErr == nil {
+		fmt.Printf(", %s, %s, %s since %s ago",
+			addedStyle.Sprintf("%d added", added),
+			changedStyle.Sprintf("%d changed", changed),
+			removedStyle.Sprintf("%d removed", removed),
+			interval)
+	}
+	fmt.Println()
+	return nil
+}
+
+// printEvents prints a line per change, as a JSON line for JSON output or a
+// YAML document for YAML output, so either can be read as a stream
+func printEvents(w io.Writer, format output.Format, changes []Change, columns []column) error {
+	now := time.Now()
+	enc := json.NewEncoder(w)
+	for _, ch := range changes {
+		switch format {
+		case output.JSON, output.NDJSON:
+			if err := enc.Encode(watchEvent{Time: now, Change: ch}); err != nil {
+				return err
+			}
+			continue
+		case output.YAML:
+			if _, err := io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+			if err := output.WriteYAML(w, watchEvent{Time: now, Change: ch}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		details := describeFieldChanges(ch.Fields)
+		if ch.Change != changeChanged {
+			details = strings.Join(rowCells(ch.it, columns), " | ")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", now.Format(time.RFC3339), ch.Change, ch.ID, details)
+	}
+	return nil
+}
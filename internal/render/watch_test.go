@@ -0,0 +1,95 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sunil-saini/astat/internal/cache"
+	"github.com/sunil-saini/astat/internal/model"
+	"github.com/sunil-saini/astat/internal/output"
+	"gopkg.in/yaml.v3"
+)
+
+func watchChanges(t *testing.T) []Change {
+	t.Helper()
+	svc, err := getService("route53-records")
+	if err != nil {
+		t.Fatal(err)
+	}
+	idColumn, err := lookupColumn(svc.Model, svc.IDField)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cctx := cache.Context{AccountID: "123456789012", Profile: "default", Region: "global"}
+	before := []item{
+		{value: model.Route53Record{ZoneName: "example.com.", Name: "api.example.com.", Type: "A", Value: "10.0.0.1", SetIdentifier: "blue"}, cctx: cctx},
+	}
+	after := []item{
+		{value: model.Route53Record{ZoneName: "example.com.", Name: "api.example.com.", Type: "A", Value: "10.0.0.2", SetIdentifier: "blue"}, cctx: cctx},
+		{value: model.Route53Record{ZoneName: "example.com.", Name: "api.example.com.", Type: "A", Value: "10.0.0.3", SetIdentifier: "green"}, cctx: cctx},
+	}
+	changes := diffItems(svc, idColumn, modelColumns(svc.Model, true), before, after)
+	if len(changes) != 2 {
+		t.Fatalf("changes = %+v, want one added and one changed", changes)
+	}
+	return changes
+}
+
+func TestPrintEventsYAML(t *testing.T) {
+	changes := watchChanges(t)
+	svc, _ := getService("route53-records")
+
+	var buf bytes.Buffer
+	if err := printEvents(&buf, output.YAML, changes, modelColumns(svc.Model, false)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "---\n") {
+		t.Errorf("output does not start with a document separator:\n%s", buf.String())
+	}
+
+	dec := yaml.NewDecoder(&buf)
+	var got []string
+	for {
+		var event map[string]any
+		err := dec.Decode(&event)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if _, ok := event["time"]; !ok {
+			t.Errorf("event without time: %v", event)
+		}
+		got = append(got, event["change"].(string))
+	}
+	if want := []string{changeAdded, changeChanged}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
+
+func TestPrintEventsJSON(t *testing.T) {
+	changes := watchChanges(t)
+	svc, _ := getService("route53-records")
+
+	var buf bytes.Buffer
+	if err := printEvents(&buf, output.JSON, changes, modelColumns(svc.Model, false)); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(changes) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(lines), len(changes), buf.String())
+	}
+	for _, line := range lines {
+		var event watchEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Errorf("line %q: %v", line, err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"unicode/utf8"
+)
+
+// Key is a key without a printable character, or KeyRune for those with one
+type Key int
+
+const (
+	KeyRune Key = iota
+	KeyEnter
+	KeyEsc
+	KeyBackspace
+	KeyTab
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyPgUp
+	KeyPgDown
+	KeyHome
+	KeyEnd
+	KeyCtrlC
+	KeyResize
+)
+
+// Event is a key pressed, or a terminal resize
+type Event struct {
+	Key  Key
+	Rune rune
+}
+
+// escapes maps the escape sequences of the special keys, as sent by xterm
+// compatible terminals
+var escapes = map[string]Key{
+	"\x1b[A":  KeyUp,
+	"\x1b[B":  KeyDown,
+	"\x1b[C":  KeyRight,
+	"\x1b[D":  KeyLeft,
+	"\x1bOA":  KeyUp,
+	"\x1bOB":  KeyDown,
+	"\x1bOC":  KeyRight,
+	"\x1bOD":  KeyLeft,
+	"\x1b[5~": KeyPgUp,
+	"\x1b[6~": KeyPgDown,
+	"\x1b[H":  KeyHome,
+	"\x1b[F":  KeyEnd,
+	"\x1b[1~": KeyHome,
+	"\x1b[4~": KeyEnd,
+	"\x1bOH":  KeyHome,
+	"\x1bOF":  KeyEnd,
+}
+
+// parseKeys splits the bytes read from a raw terminal into events. It
+// returns the escape sequence or character cut off at the end of b, to be
+// parsed along with the next bytes read, unless flush is set. A lone escape
+// byte is the Esc key, unknown escape sequences are dropped
+func parseKeys(b []byte, flush bool) ([]Event, []byte) {
+	var events []Event
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b:
+			n, complete := escapeLen(b)
+			if !complete && !flush {
+				return events, b
+			}
+			if n == 1 {
+				events = append(events, Event{Key: KeyEsc})
+			} else if key, ok := escapes[string(b[:n])]; ok {
+				events = append(events, Event{Key: key})
+			}
+			b = b[n:]
+		case c == '\r' || c == '\n':
+			events = append(events, Event{Key: KeyEnter})
+			b = b[1:]
+		case c == 0x7f || c == 0x08:
+			events = append(events, Event{Key: KeyBackspace})
+			b = b[1:]
+		case c == '\t':
+			events = append(events, Event{Key: KeyTab})
+			b = b[1:]
+		case c == 0x03:
+			events = append(events, Event{Key: KeyCtrlC})
+			b = b[1:]
+		case c < 0x20:
+			b = b[1:]
+		default:
+			if !utf8.FullRune(b) && !flush {
+				return events, b
+			}
+			r, size := utf8.DecodeRune(b)
+			events = append(events, Event{Key: KeyRune, Rune: r})
+			b = b[size:]
+		}
+	}
+	return events, nil
+}
+
+// escapeLen returns the length of the escape sequence b starts with: ESC,
+// then [ or O, then parameters up to a final letter or ~. The sequence is
+// incomplete when b ends before its final byte, or right after ESC
+func escapeLen(b []byte) (int, bool) {
+	if len(b) < 2 {
+		return 1, false
+	}
+	if b[1] != '[' && b[1] != 'O' {
+		return 1, true
+	}
+	for i := 2; i < len(b); i++ {
+		if c := b[i]; (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || c == '~' {
+			return i + 1, true
+		}
+	}
+	return len(b), false
+}
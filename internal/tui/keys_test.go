@@ -0,0 +1,82 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []Event
+		rest string
+	}{
+		{name: "runes", in: "ab", want: []Event{{Key: KeyRune, Rune: 'a'}, {Key: KeyRune, Rune: 'b'}}},
+		{name: "multibyte rune", in: "é", want: []Event{{Key: KeyRune, Rune: 'é'}}},
+		{name: "enter", in: "\r", want: []Event{{Key: KeyEnter}}},
+		{name: "newline", in: "\n", want: []Event{{Key: KeyEnter}}},
+		{name: "backspace", in: "\x7f\x08", want: []Event{{Key: KeyBackspace}, {Key: KeyBackspace}}},
+		{name: "tab", in: "\t", want: []Event{{Key: KeyTab}}},
+		{name: "ctrl-c", in: "\x03", want: []Event{{Key: KeyCtrlC}}},
+		{name: "other control bytes", in: "\x01\x02x", want: []Event{{Key: KeyRune, Rune: 'x'}}},
+		{name: "arrows", in: "\x1b[A\x1b[B\x1b[C\x1b[D", want: []Event{{Key: KeyUp}, {Key: KeyDown}, {Key: KeyRight}, {Key: KeyLeft}}},
+		{name: "application arrows", in: "\x1bOA\x1bOD", want: []Event{{Key: KeyUp}, {Key: KeyLeft}}},
+		{name: "pages", in: "\x1b[5~\x1b[6~", want: []Event{{Key: KeyPgUp}, {Key: KeyPgDown}}},
+		{name: "home and end", in: "\x1b[H\x1b[F\x1b[1~\x1b[4~\x1bOH\x1bOF", want: []Event{{Key: KeyHome}, {Key: KeyEnd}, {Key: KeyHome}, {Key: KeyEnd}, {Key: KeyHome}, {Key: KeyEnd}}},
+		{name: "unknown sequence dropped", in: "\x1b[1;5Ax", want: []Event{{Key: KeyRune, Rune: 'x'}}},
+		{name: "esc before rune", in: "\x1bq", want: []Event{{Key: KeyEsc}, {Key: KeyRune, Rune: 'q'}}},
+		{name: "double esc", in: "\x1b\x1b[A", want: []Event{{Key: KeyEsc}, {Key: KeyUp}}},
+		{name: "lone esc waits", in: "\x1b", rest: "\x1b"},
+		{name: "csi prefix waits", in: "j\x1b[", want: []Event{{Key: KeyRune, Rune: 'j'}}, rest: "\x1b["},
+		{name: "ss3 prefix waits", in: "\x1bO", rest: "\x1bO"},
+		{name: "parameters wait", in: "\x1b[5", rest: "\x1b[5"},
+		{name: "cut rune waits", in: "a\xc3", want: []Event{{Key: KeyRune, Rune: 'a'}}, rest: "\xc3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest := parseKeys([]byte(tt.in), false)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("events = %v, want %v", got, tt.want)
+			}
+			if string(rest) != tt.rest {
+				t.Errorf("rest = %q, want %q", rest, tt.rest)
+			}
+		})
+	}
+}
+
+func TestParseKeysFlush(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []Event
+	}{
+		{name: "lone esc", in: "\x1b", want: []Event{{Key: KeyEsc}}},
+		{name: "incomplete sequence dropped", in: "\x1b[5", want: nil},
+		{name: "cut rune", in: "\xc3", want: []Event{{Key: KeyRune, Rune: utf8.RuneError}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest := parseKeys([]byte(tt.in), true)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("events = %v, want %v", got, tt.want)
+			}
+			if rest != nil {
+				t.Errorf("rest = %q, want none", rest)
+			}
+		})
+	}
+}
+
+func TestParseKeysSplitSequence(t *testing.T) {
+	events, rest := parseKeys([]byte("\x1b"), false)
+	if len(events) != 0 {
+		t.Fatalf("events = %v, want none before the sequence completes", events)
+	}
+	events, rest = parseKeys(append(rest, "[A"...), false)
+	if want := []Event{{Key: KeyUp}}; !slices.Equal(events, want) || rest != nil {
+		t.Errorf("events = %v, rest = %q, want %v", events, rest, want)
+	}
+}
@@ -0,0 +1,171 @@
+// Package tui holds the terminal primitives of the full-screen browser: raw
+// mode, key events and drawing whole frames with ANSI escape sequences
+package tui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	enterAltScreen = "\033[?1049h"
+	exitAltScreen  = "\033[?1049l"
+	hideCursor     = "\033[?25l"
+	showCursor     = "\033[?25h"
+	cursorHome     = "\033[H"
+	clearToEnd     = "\033[J"
+
+	// escapeTimeout is how long the rest of an escape sequence split across
+	// reads is waited for, before a lone ESC is taken as the Esc key
+	escapeTimeout = 50 * time.Millisecond
+)
+
+// Screen is the terminal switched to raw mode and to its alternate screen
+type Screen struct {
+	in    *os.File
+	out   *bufio.Writer
+	fd    int
+	state *term.State
+
+	events chan Event
+	winch  chan os.Signal
+	done   chan struct{}
+}
+
+// NewScreen takes over the terminal until Close is called. It fails when
+// stdin or stdout is not a terminal
+func NewScreen() (*Screen, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return nil, errors.New("an interactive terminal is required")
+	}
+
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("cannot switch terminal to raw mode: %w", err)
+	}
+
+	s := &Screen{
+		in:     os.Stdin,
+		out:    bufio.NewWriterSize(os.Stdout, 64*1024),
+		fd:     fd,
+		state:  state,
+		events: make(chan Event, 16),
+		winch:  make(chan os.Signal, 1),
+		done:   make(chan struct{}),
+	}
+	s.out.WriteString(enterAltScreen + hideCursor)
+	s.out.Flush()
+
+	signal.Notify(s.winch, syscall.SIGWINCH)
+	chunks := make(chan []byte)
+	go s.read(chunks)
+	go s.decode(chunks)
+	return s, nil
+}
+
+// Close gives the terminal back in the state it was found in
+func (s *Screen) Close() {
+	close(s.done)
+	signal.Stop(s.winch)
+	s.out.WriteString(showCursor + exitAltScreen)
+	s.out.Flush()
+	_ = term.Restore(s.fd, s.state)
+}
+
+// Events delivers the keys pressed and the terminal resizes
+func (s *Screen) Events() <-chan Event {
+	return s.events
+}
+
+// Size returns the width and height of the terminal
+func (s *Screen) Size() (int, int) {
+	w, h, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || w <= 0 || h <= 0 {
+		return 80, 24
+	}
+	return w, h
+}
+
+// Draw replaces the screen content with lines, which must already fit the
+// terminal width
+func (s *Screen) Draw(lines []string) {
+	s.out.WriteString(cursorHome)
+	s.out.WriteString(strings.Join(lines, "\r\n"))
+	s.out.WriteString(clearToEnd)
+	s.out.Flush()
+}
+
+// read passes the bytes typed on to decode, until stdin fails or the screen
+// is closed
+func (s *Screen) read(chunks chan<- []byte) {
+	defer close(chunks)
+	for {
+		buf := make([]byte, 256)
+		n, err := s.in.Read(buf)
+		if err != nil {
+			return
+		}
+		select {
+		case chunks <- buf[:n]:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// decode turns the bytes read and the terminal resizes into events. It is
+// the only sender on the events channel, closed once stdin fails
+func (s *Screen) decode(chunks <-chan []byte) {
+	var pending []byte
+	var timeout <-chan time.Time
+	for {
+		var events []Event
+		select {
+		case <-s.done:
+			return
+		case <-s.winch:
+			events = []Event{{Key: KeyResize}}
+		case b, ok := <-chunks:
+			if !ok {
+				events, _ = parseKeys(pending, true)
+				s.send(events)
+				close(s.events)
+				return
+			}
+			events, pending = parseKeys(slices.Concat(pending, b), false)
+			timeout = nil
+			if len(pending) > 0 {
+				timeout = time.After(escapeTimeout)
+			}
+		case <-timeout:
+			events, pending = parseKeys(pending, true)
+			timeout = nil
+		}
+		if !s.send(events) {
+			return
+		}
+	}
+}
+
+// send delivers events unless the screen is closed first, reporting whether
+// it is still open
+func (s *Screen) send(events []Event) bool {
+	for _, ev := range events {
+		select {
+		case s.events <- ev:
+		case <-s.done:
+			return false
+		}
+	}
+	return true
+}
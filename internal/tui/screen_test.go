@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func newTestScreen() (*Screen, chan []byte) {
+	s := &Screen{
+		events: make(chan Event),
+		winch:  make(chan os.Signal, 1),
+		done:   make(chan struct{}),
+	}
+	chunks := make(chan []byte)
+	go s.decode(chunks)
+	return s, chunks
+}
+
+func nextEvent(t *testing.T, s *Screen) Event {
+	t.Helper()
+	select {
+	case ev := <-s.events:
+		return ev
+	case <-time.After(time.Second):
+		t.Fatal("no event")
+		return Event{}
+	}
+}
+
+func TestDecodeSplitEscape(t *testing.T) {
+	s, chunks := newTestScreen()
+	defer close(s.done)
+
+	chunks <- []byte("\x1b")
+	chunks <- []byte("[B")
+	if ev := nextEvent(t, s); ev.Key != KeyDown {
+		t.Errorf("event = %v, want KeyDown", ev)
+	}
+}
+
+func TestDecodeEscTimeout(t *testing.T) {
+	s, chunks := newTestScreen()
+	defer close(s.done)
+
+	start := time.Now()
+	chunks <- []byte("\x1b")
+	if ev := nextEvent(t, s); ev.Key != KeyEsc {
+		t.Errorf("event = %v, want KeyEsc", ev)
+	}
+	if elapsed := time.Since(start); elapsed < escapeTimeout {
+		t.Errorf("Esc delivered after %s, before the escape timeout", elapsed)
+	}
+}
+
+func TestDecodeResize(t *testing.T) {
+	s, _ := newTestScreen()
+	defer close(s.done)
+
+	s.winch <- os.Interrupt
+	if ev := nextEvent(t, s); ev.Key != KeyResize {
+		t.Errorf("event = %v, want KeyResize", ev)
+	}
+}
+
+func TestDecodeEOF(t *testing.T) {
+	s, chunks := newTestScreen()
+	defer close(s.done)
+
+	chunks <- []byte("q\x1b")
+	close(chunks)
+	if ev := nextEvent(t, s); ev.Key != KeyRune || ev.Rune != 'q' {
+		t.Errorf("event = %v, want q", ev)
+	}
+	if ev := nextEvent(t, s); ev.Key != KeyEsc {
+		t.Errorf("event = %v, want KeyEsc flushed at EOF", ev)
+	}
+	select {
+	case _, ok := <-s.events:
+		if ok {
+			t.Error("events not closed at EOF")
+		}
+	case <-time.After(time.Second):
+		t.Error("events not closed at EOF")
+	}
+}
+
+func TestDecodeStopsOnClose(t *testing.T) {
+	s := &Screen{
+		events: make(chan Event),
+		winch:  make(chan os.Signal, 1),
+		done:   make(chan struct{}),
+	}
+	chunks := make(chan []byte, 1)
+	stopped := make(chan struct{})
+	go func() {
+		s.decode(chunks)
+		close(stopped)
+	}()
+
+	// Nobody reads the events, decode must not block on them once closed
+	chunks <- []byte("abc")
+	close(s.done)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("decode still running after close")
+	}
+}
@@ -0,0 +1,44 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// Styles wrap text in SGR escape sequences. Text is fitted to its width
+// before being styled, since the sequences take no room on screen
+var (
+	Bold      = style("1")
+	Dim       = style("2")
+	Underline = style("4")
+	Reverse   = style("7")
+	Cyan      = style("96")
+	Green     = style("92")
+	Yellow    = style("93")
+	Red       = style("91")
+)
+
+func style(code string) func(string) string {
+	return func(s string) string {
+		return "\033[" + code + "m" + s + "\033[0m"
+	}
+}
+
+// Fit truncates s to width columns, ending it with … when cut, and pads it
+// with spaces up to width
+func Fit(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	s = strings.NewReplacer("\n", " ", "\r", " ", "\t", " ").Replace(s)
+	if runewidth.StringWidth(s) > width {
+		s = runewidth.Truncate(s, width, "…")
+	}
+	return s + strings.Repeat(" ", width-runewidth.StringWidth(s))
+}
+
+// Width returns the number of columns s takes on screen
+func Width(s string) int {
+	return runewidth.StringWidth(s)
+}